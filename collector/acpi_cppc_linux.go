@@ -0,0 +1,92 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noacpi_cppc
+// +build !noacpi_cppc
+
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const cppcSubsystem = "cppc"
+
+type acpiCppcCollector struct {
+	perfs  []acpiCppcPerf
+	logger log.Logger
+}
+
+type acpiCppcPerf struct {
+	file string
+	desc typedDesc
+}
+
+func init() {
+	registerCollector("acpi_cppc", defaultDisabled, NewACPICppcCollector)
+}
+
+// NewACPICppcCollector returns a new Collector exposing the ACPI
+// Collaborative Processor Performance Control capabilities per CPU.
+func NewACPICppcCollector(logger log.Logger) (Collector, error) {
+	c := &acpiCppcCollector{logger: logger}
+	for _, p := range []struct{ file, help string }{
+		{"highest_perf", "Highest performance level of the CPU (boost)."},
+		{"nominal_perf", "Maximum sustained performance level of the CPU."},
+		{"lowest_nonlinear_perf", "Lowest performance level of the CPU with non-linear power savings."},
+		{"lowest_perf", "Lowest performance level of the CPU."},
+	} {
+		c.perfs = append(c.perfs, acpiCppcPerf{p.file, typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cppcSubsystem, p.file),
+			p.help,
+			[]string{"cpu"}, nil,
+		), prometheus.GaugeValue}})
+	}
+	return c, nil
+}
+
+// Update implements Collector and exposes the CPPC performance levels.
+func (c *acpiCppcCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*/acpi_cppc"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No ACPI CPPC data found")
+		return ErrNoData
+	}
+
+	for _, dir := range dirs {
+		cpu := strings.TrimPrefix(filepath.Base(filepath.Dir(dir)), "cpu")
+		for _, p := range c.perfs {
+			file := filepath.Join(dir, p.file)
+			data, err := readStringFromFile(file)
+			if err != nil {
+				return err
+			}
+			value, err := strconv.ParseFloat(data, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value in %s: %w", file, err)
+			}
+			ch <- p.desc.mustNewConstMetric(value, cpu)
+		}
+	}
+	return nil
+}
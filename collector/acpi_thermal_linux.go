@@ -0,0 +1,138 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noacpi_thermal
+// +build !noacpi_thermal
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type acpiThermalCollector struct {
+	temp, trip typedDesc
+	logger     log.Logger
+}
+
+func init() {
+	registerCollector("acpi_thermal", defaultDisabled, NewACPIThermalCollector)
+}
+
+// NewACPIThermalCollector returns a new Collector exposing the legacy
+// /proc/acpi/thermal_zone/ temperatures (CONFIG_ACPI_PROCFS, removed in
+// Linux 2.6.38).
+func NewACPIThermalCollector(logger log.Logger) (Collector, error) {
+	return &acpiThermalCollector{
+		temp: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "acpi_thermal", "zone_celsius"),
+			"Current temperature of the ACPI thermal zone.",
+			[]string{"zone"}, nil,
+		), prometheus.GaugeValue},
+		trip: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "acpi_thermal", "trip_celsius"),
+			"Trip point temperature of the ACPI thermal zone.",
+			[]string{"zone", "trip_type"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *acpiThermalCollector) Update(ch chan<- prometheus.Metric) error {
+	zones, err := ioutil.ReadDir(procFilePath("acpi/thermal_zone"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "No legacy ACPI thermal zones found")
+			return ErrNoData
+		}
+		return err
+	}
+
+	for _, zone := range zones {
+		dir := procFilePath(filepath.Join("acpi/thermal_zone", zone.Name()))
+		temps, err := readACPIThermalFile(filepath.Join(dir, "temperature"))
+		if err == nil {
+			if t, ok := temps["temperature"]; ok {
+				ch <- c.temp.mustNewConstMetric(t, zone.Name())
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		trips, err := readACPIThermalFile(filepath.Join(dir, "trip_points"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for tripType, t := range trips {
+			ch <- c.trip.mustNewConstMetric(t, zone.Name(), tripType)
+		}
+	}
+	return nil
+}
+
+func readACPIThermalFile(path string) (map[string]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	temps, err := parseACPIThermal(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", path, err)
+	}
+	return temps, nil
+}
+
+// parseACPIThermal parses lines like "critical (S5):  100 C" or
+// "active[0]:  70 C: devices=..." and returns the temperatures by name, e.g.
+// "critical" and "active[0]". Lines without a temperature get skipped.
+func parseACPIThermal(r io.Reader) (map[string]float64, error) {
+	temps := map[string]float64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) != 2 || fields[1] != "C" {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid temperature in line %q: %w", scanner.Text(), err)
+		}
+		name := strings.Fields(parts[0])
+		if len(name) == 0 {
+			continue
+		}
+		temps[name[0]] = value
+	}
+	return temps, scanner.Err()
+}
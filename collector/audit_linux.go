@@ -0,0 +1,135 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noaudit
+// +build !noaudit
+
+package collector
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	auditSubsystem = "audit"
+
+	// AUDIT_GET from linux/audit.h
+	auditGet = 1000
+
+	// Offsets of the __u32 fields of struct audit_status (linux/audit.h)
+	// used by this collector.
+	auditStatusEnabled               = 1
+	auditStatusLost                  = 6
+	auditStatusBacklog               = 7
+	auditStatusBacklogWaitTimeActual = 10
+)
+
+var auditKernelHZ = kingpin.Flag("collector.audit.kernel-hz", "The kernel's CONFIG_HZ, used to convert the backlog wait time from jiffies to seconds.").Default("250").Int()
+
+type auditCollector struct {
+	enabled, lost, backlog, backlogWaitTime typedDesc
+	logger                                  log.Logger
+}
+
+func init() {
+	registerCollector("audit", defaultDisabled, NewAuditCollector)
+}
+
+// NewAuditCollector returns a new Collector exposing the status of the
+// kernel audit subsystem.
+func NewAuditCollector(logger log.Logger) (Collector, error) {
+	if *auditKernelHZ <= 0 {
+		return nil, fmt.Errorf("invalid kernel HZ %d", *auditKernelHZ)
+	}
+	return &auditCollector{
+		enabled: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, auditSubsystem, "enabled"),
+			"Whether auditing is disabled (0), enabled (1) or enabled and locked (2).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		lost: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, auditSubsystem, "lost_total"),
+			"Number of audit records lost.",
+			nil, nil,
+		), prometheus.CounterValue},
+		backlog: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, auditSubsystem, "backlog"),
+			"Number of audit records waiting in the backlog queue.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		backlogWaitTime: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, auditSubsystem, "backlog_wait_time_seconds"),
+			"Time processes spent waiting for space in the backlog queue.",
+			nil, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the audit status as reported by
+// the kernel on an AUDIT_GET request.
+func (c *auditCollector) Update(ch chan<- prometheus.Metric) error {
+	status, err := getAuditStatus()
+	if err != nil {
+		return fmt.Errorf("couldn't get audit status: %w", err)
+	}
+	if len(status) <= auditStatusBacklog {
+		return fmt.Errorf("audit status too short: %d fields", len(status))
+	}
+	ch <- c.enabled.mustNewConstMetric(float64(status[auditStatusEnabled]))
+	ch <- c.lost.mustNewConstMetric(float64(status[auditStatusLost]))
+	ch <- c.backlog.mustNewConstMetric(float64(status[auditStatusBacklog]))
+	// kernels < 5.9 do not report the actual backlog wait time
+	if len(status) > auditStatusBacklogWaitTimeActual {
+		ch <- c.backlogWaitTime.mustNewConstMetric(float64(status[auditStatusBacklogWaitTimeActual]) / float64(*auditKernelHZ))
+	}
+	return nil
+}
+
+// getAuditStatus sends an AUDIT_GET request to the kernel and returns the
+// fields of the struct audit_status reply. Requires CAP_AUDIT_CONTROL.
+func getAuditStatus() ([]uint32, error) {
+	conn, err := netlink.Dial(unix.NETLINK_AUDIT, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	msgs, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  auditGet,
+			Flags: netlink.Request,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range msgs {
+		if m.Header.Type != auditGet {
+			continue
+		}
+		status := make([]uint32, len(m.Data)/4)
+		for i := range status {
+			status[i] = nlenc.Uint32(m.Data[i*4 : i*4+4])
+		}
+		return status, nil
+	}
+	return nil, fmt.Errorf("no AUDIT_GET reply received")
+}
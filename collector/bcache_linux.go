@@ -18,8 +18,10 @@ package collector
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs/bcache"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -66,6 +68,10 @@ func (c *bcacheCollector) Update(ch chan<- prometheus.Metric) error {
 	if err != nil {
 		return fmt.Errorf("failed to retrieve bcache stats: %w", err)
 	}
+	if len(stats) == 0 {
+		level.Debug(c.logger).Log("msg", "No bcache devices found")
+		return ErrNoData
+	}
 
 	for _, s := range stats {
 		c.updateBcacheStats(ch, s)
@@ -282,6 +288,21 @@ func (c *bcacheCollector) updateBcacheStats(ch chan<- prometheus.Metric, s *bcac
 		metrics := bcachePeriodStatsToMetric(&bdev.Total, bdev.Name)
 		allMetrics = append(allMetrics, metrics...)
 
+		// not parsed by procfs
+		ratio, err := readUintFromFile(sysFilePath(filepath.Join("fs/bcache", s.Name, bdev.Name, "stats_total", "cache_hit_ratio")))
+		if err == nil {
+			allMetrics = append(allMetrics, bcacheMetric{
+				name:            "cache_hit_ratio",
+				desc:            "Ratio of cache hits to all IO as bcache sees them (0..1).",
+				value:           float64(ratio) / 100,
+				metricType:      prometheus.GaugeValue,
+				extraLabel:      []string{"backing_device"},
+				extraLabelValue: bdev.Name,
+			})
+		} else {
+			level.Debug(c.logger).Log("msg", "Failed to read cache_hit_ratio", "backing_device", bdev.Name, "err", err)
+		}
+
 	}
 
 	for _, cache := range s.Caches {
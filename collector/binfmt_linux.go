@@ -0,0 +1,128 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nobinfmt
+// +build !nobinfmt
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const binfmtSubsystem = "binfmt"
+
+type binfmtCollector struct {
+	info, active typedDesc
+	logger       log.Logger
+}
+
+type binfmtEntry struct {
+	enabled     bool
+	interpreter string
+	flags       string
+}
+
+func init() {
+	registerCollector("binfmt", defaultDisabled, NewBinfmtCollector)
+}
+
+// NewBinfmtCollector returns a new Collector exposing registered binary
+// formats.
+func NewBinfmtCollector(logger log.Logger) (Collector, error) {
+	return &binfmtCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, binfmtSubsystem, "interpreter_info"),
+			"Registered binary format interpreter from /proc/sys/fs/binfmt_misc/<name>, value is 1 if enabled, 0 otherwise.",
+			[]string{"name", "interpreter", "flags"}, nil,
+		), prometheus.GaugeValue},
+		active: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, binfmtSubsystem, "interpreters_total"),
+			"Number of enabled binary format interpreters.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes binfmt_misc registrations.
+func (c *binfmtCollector) Update(ch chan<- prometheus.Metric) error {
+	dir := procFilePath("sys/fs/binfmt_misc")
+	// The directory exists even if binfmt_misc is not mounted.
+	if _, err := os.Stat(filepath.Join(dir, "status")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "binfmt_misc not mounted", "dir", dir)
+			return ErrNoData
+		}
+		return err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	active := 0
+	for _, file := range files {
+		if file.Name() == "register" || file.Name() == "status" {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return err
+		}
+		entry, err := parseBinfmtEntry(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("couldn't parse binfmt entry %s: %w", file.Name(), err)
+		}
+		value := 0.0
+		if entry.enabled {
+			value = 1
+			active++
+		}
+		ch <- c.info.mustNewConstMetric(value, file.Name(), entry.interpreter, entry.flags)
+	}
+	ch <- c.active.mustNewConstMetric(float64(active))
+	return nil
+}
+
+func parseBinfmtEntry(r io.Reader) (binfmtEntry, error) {
+	var (
+		entry   binfmtEntry
+		scanner = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "enabled":
+			entry.enabled = true
+		case strings.HasPrefix(line, "interpreter "):
+			entry.interpreter = strings.TrimPrefix(line, "interpreter ")
+		case strings.HasPrefix(line, "flags:"):
+			entry.flags = strings.TrimSpace(strings.TrimPrefix(line, "flags:"))
+		}
+	}
+	return entry, scanner.Err()
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nobinfmt
+// +build !nobinfmt
+
+package collector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBinfmtEntry(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want binfmtEntry
+	}{
+		{`enabled
+interpreter /usr/bin/qemu-aarch64-static
+flags: OCF
+offset 0
+magic 7f454c460201010000000000000000000200b700
+mask ffffffffffffff00fffffffffffffffffeffffff
+`, binfmtEntry{enabled: true, interpreter: "/usr/bin/qemu-aarch64-static", flags: "OCF"}},
+		{`disabled
+interpreter /usr/bin/jexec
+flags: 
+extension .jar
+`, binfmtEntry{interpreter: "/usr/bin/jexec"}},
+	} {
+		got, err := parseBinfmtEntry(strings.NewReader(tc.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("want %+v, got %+v", tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nobio_stats && !nodiskstats
+// +build !nobio_stats,!nodiskstats
+
+package collector
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type bioStatsCollector struct {
+	queueDepth, blockSize typedDesc
+	ignoredDevicesPattern *regexp.Regexp
+	logger                log.Logger
+}
+
+func init() {
+	registerCollector("bio_stats", defaultDisabled, NewBioStatsCollector)
+}
+
+// NewBioStatsCollector returns a new Collector exposing the request queue
+// settings of block devices. Devices ignored by the diskstats collector
+// (--collector.diskstats.ignored-devices) get skipped, so it needs to be
+// built in.
+func NewBioStatsCollector(logger log.Logger) (Collector, error) {
+	return &bioStatsCollector{
+		queueDepth: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blockdevice", "queue_depth_max"),
+			"Maximum number of read resp. write requests the block layer allocates for the device (nr_requests).",
+			[]string{"device"}, nil,
+		), prometheus.GaugeValue},
+		blockSize: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blockdevice", "physical_block_size_bytes"),
+			"Smallest unit the device can write without read-modify-write.",
+			[]string{"device"}, nil,
+		), prometheus.GaugeValue},
+		ignoredDevicesPattern: regexp.MustCompile(*ignoredDevices),
+		logger:                logger,
+	}, nil
+}
+
+// Update implements Collector and exposes /sys/block/<dev>/queue/. Devices
+// without a request queue (e.g. some device mapper targets) get skipped.
+func (c *bioStatsCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("block/*/queue"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No block device queues found")
+		return ErrNoData
+	}
+	for _, dir := range dirs {
+		device := filepath.Base(filepath.Dir(dir))
+		if c.ignoredDevicesPattern.MatchString(device) {
+			level.Debug(c.logger).Log("msg", "Ignoring device", "device", device, "pattern", c.ignoredDevicesPattern)
+			continue
+		}
+		if v, err := readUintFromFile(filepath.Join(dir, "nr_requests")); err == nil {
+			ch <- c.queueDepth.mustNewConstMetric(float64(v), device)
+		}
+		if v, err := readUintFromFile(filepath.Join(dir, "physical_block_size")); err == nil {
+			ch <- c.blockSize.mustNewConstMetric(float64(v), device)
+		}
+	}
+	return nil
+}
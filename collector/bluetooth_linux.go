@@ -0,0 +1,147 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nobluetooth
+// +build !nobluetooth
+
+package collector
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+// hciGetConnList is the HCIGETCONNLIST ioctl, i.e. _IOR('H', 212, int) of
+// <bluetooth/hci.h>.
+var hciGetConnList = ioctlRead('H', 212, 4)
+
+const (
+	// max. number of connections to query per adapter
+	hciMaxConn = 64
+	// sizeof(struct hci_conn_info)
+	hciConnInfoSize = 16
+)
+
+type bluetoothCollector struct {
+	info, connections, total typedDesc
+	logger                   log.Logger
+}
+
+func init() {
+	registerCollector("bluetooth", defaultDisabled, NewBluetoothCollector)
+}
+
+// NewBluetoothCollector returns a new Collector exposing Bluetooth adapter
+// stats.
+func NewBluetoothCollector(logger log.Logger) (Collector, error) {
+	return &bluetoothCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bluetooth", "adapter_info"),
+			"Information about the Bluetooth adapter.",
+			[]string{"hci", "address", "type", "bus"}, nil,
+		), prometheus.GaugeValue},
+		connections: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bluetooth", "adapter_connections"),
+			"Number of active connections of the Bluetooth adapter.",
+			[]string{"hci"}, nil,
+		), prometheus.GaugeValue},
+		total: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bluetooth", "adapters_total"),
+			"Number of Bluetooth adapters.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Attributes not provided by the kernel are
+// reported as empty labels.
+func (c *bluetoothCollector) Update(ch chan<- prometheus.Metric) error {
+	adapters, err := filepath.Glob(sysFilePath("class/bluetooth/hci[0-9]*"))
+	if err != nil {
+		return err
+	}
+	if len(adapters) == 0 {
+		level.Debug(c.logger).Log("msg", "No Bluetooth adapters found")
+		return ErrNoData
+	}
+
+	fd, err := unix.Socket(unix.AF_BLUETOOTH, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.BTPROTO_HCI)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Failed to open HCI socket, connections not available", "err", err)
+		fd = -1
+	} else {
+		defer unix.Close(fd)
+	}
+
+	for _, adapter := range adapters {
+		hci := filepath.Base(adapter)
+		labels := []string{hci}
+		for _, file := range []string{"address", "type", "bus"} {
+			value, _ := readStringFromFile(filepath.Join(adapter, file))
+			labels = append(labels, value)
+		}
+		ch <- c.info.mustNewConstMetric(1, labels...)
+
+		if fd < 0 {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(hci, "hci"), 10, 16)
+		if err != nil {
+			continue
+		}
+		n, err := hciConnCount(fd, uint16(id))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to get HCI connection list", "hci", hci, "err", err)
+			continue
+		}
+		ch <- c.connections.mustNewConstMetric(float64(n), hci)
+	}
+	ch <- c.total.mustNewConstMetric(float64(len(adapters)))
+	return nil
+}
+
+// ioctlRead returns _IOR(typ, nr, size) for the current architecture. The
+// size field has 14 bits and _IOC_READ gets shifted by 30 bits, except on
+// mips, ppc and sparc, which use 13 bits resp. 29 bits.
+func ioctlRead(typ, nr, size uintptr) uintptr {
+	dirShift := uintptr(30)
+	switch runtime.GOARCH {
+	case "mips", "mipsle", "mips64", "mips64le", "ppc", "ppc64", "ppc64le", "sparc", "sparc64":
+		dirShift = 29
+	}
+	const iocRead = 2
+	return iocRead<<dirShift | size<<16 | typ<<8 | nr
+}
+
+// hciConnCount returns the number of connections of the given adapter using
+// the HCIGETCONNLIST ioctl, i.e. struct hci_conn_list_req { __u16 dev_id;
+// __u16 conn_num; struct hci_conn_info conn_info[]; }.
+func hciConnCount(fd int, id uint16) (uint16, error) {
+	req := make([]byte, 4+hciMaxConn*hciConnInfoSize)
+	// the kernel uses host byte order
+	*(*uint16)(unsafe.Pointer(&req[0])) = id
+	*(*uint16)(unsafe.Pointer(&req[2])) = hciMaxConn
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), hciGetConnList, uintptr(unsafe.Pointer(&req[0]))); errno != 0 {
+		return 0, errno
+	}
+	return *(*uint16)(unsafe.Pointer(&req[2])), nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nobluetooth
+// +build !nobluetooth
+
+package collector
+
+import (
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestIoctlRead(t *testing.T) {
+	// BLKGETSIZE64 is _IOR(0x12, 114, size_t)
+	if got, want := ioctlRead(0x12, 114, unsafe.Sizeof(uintptr(0))), uintptr(unix.BLKGETSIZE64); got != want {
+		t.Errorf("want %#x, got %#x", want, got)
+	}
+}
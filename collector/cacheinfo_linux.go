@@ -0,0 +1,121 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocacheinfo
+// +build !nocacheinfo
+
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cacheinfoCollector struct {
+	info, size typedDesc
+	logger     log.Logger
+}
+
+func init() {
+	registerCollector("cacheinfo", defaultDisabled, NewCacheinfoCollector)
+}
+
+// NewCacheinfoCollector returns a new Collector exposing the CPU caches.
+func NewCacheinfoCollector(logger log.Logger) (Collector, error) {
+	labels := []string{"package", "cache_id", "level", "type"}
+	return &cacheinfoCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "cache_info"),
+			"Geometry of the CPU cache and the CPUs sharing it.",
+			append(labels, "line_size", "sets", "ways", "shared_cpus"), nil,
+		), prometheus.GaugeValue},
+		size: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "cache_size_bytes"),
+			"Size of the CPU cache in bytes.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Each cache shows up in the cache/index*
+// directory of every CPU sharing it, so caches get deduplicated by level,
+// type and shared_cpu_list. If the kernel has no id file for a cache (e.g.
+// Linux < 4.11), the shared_cpu_list is used as cache_id.
+func (c *cacheinfoCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*/cache/index[0-9]*"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No CPU cache information found")
+		return ErrNoData
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		read := func(name string) string {
+			value, err := readStringFromFile(filepath.Join(dir, name))
+			if err != nil {
+				return ""
+			}
+			return value
+		}
+		lvl, typ, shared := read("level"), strings.ToLower(read("type")), read("shared_cpu_list")
+		key := lvl + "/" + typ + "/" + shared
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		id := read("id")
+		if id == "" {
+			id = shared
+		}
+		pkg := readCPUTopologyID(filepath.Join(dir, "../../topology"), "physical_package_id")
+		ch <- c.info.mustNewConstMetric(1, pkg, id, lvl, typ,
+			read("coherency_line_size"), read("number_of_sets"), read("ways_of_associativity"), shared)
+
+		size, err := parseCacheSize(read("size"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Invalid cache size", "dir", dir, "err", err)
+			continue
+		}
+		ch <- c.size.mustNewConstMetric(float64(size), pkg, id, lvl, typ)
+	}
+	return nil
+}
+
+// parseCacheSize converts sizes like "48K" or "32M" into bytes.
+func parseCacheSize(s string) (uint64, error) {
+	factor := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		factor = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		factor = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		factor = 1 << 30
+	}
+	value, err := strconv.ParseUint(strings.TrimRight(s, "KMG"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	return value * factor, nil
+}
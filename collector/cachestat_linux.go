@@ -0,0 +1,195 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocachestat
+// +build !nocachestat
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const pagecacheSubsystem = "pagecache"
+
+var cachestatPaths = kingpin.Flag("collector.cachestat.paths", "Comma separated list of files or mount points to query via cachestat(2). Pseudo filesystems like proc or sysfs get skipped, they have no page cache.").Default("").String()
+
+// cachestatSyscalls are the cachestat(2) syscall numbers by GOARCH. The
+// package unix does not provide them (yet).
+var cachestatSyscalls = map[string]uintptr{
+	"386":      451,
+	"amd64":    451,
+	"arm":      451,
+	"arm64":    451,
+	"loong64":  451,
+	"mips":     4451,
+	"mipsle":   4451,
+	"mips64":   5451,
+	"mips64le": 5451,
+	"ppc64":    451,
+	"ppc64le":  451,
+	"riscv64":  451,
+	"s390x":    451,
+}
+
+// cachestatPseudoFS are the magic numbers of filesystems without page cache.
+var cachestatPseudoFS = map[uint32]bool{
+	unix.BINFMTFS_MAGIC:      true,
+	unix.BPF_FS_MAGIC:        true,
+	unix.CGROUP2_SUPER_MAGIC: true,
+	unix.CGROUP_SUPER_MAGIC:  true,
+	unix.DEBUGFS_MAGIC:       true,
+	unix.DEVPTS_SUPER_MAGIC:  true,
+	unix.EFIVARFS_MAGIC:      true,
+	unix.NSFS_MAGIC:          true,
+	unix.PROC_SUPER_MAGIC:    true,
+	unix.PSTOREFS_MAGIC:      true,
+	unix.SECURITYFS_MAGIC:    true,
+	unix.SELINUX_MAGIC:       true,
+	unix.SYSFS_MAGIC:         true,
+	unix.TRACEFS_MAGIC:       true,
+}
+
+// cachestatRange is struct cachestat_range from linux/mman.h.
+type cachestatRange struct {
+	off uint64
+	len uint64
+}
+
+// cachestat is struct cachestat from linux/mman.h.
+type cachestat struct {
+	cache           uint64
+	dirty           uint64
+	writeback       uint64
+	evicted         uint64
+	recentlyEvicted uint64
+}
+
+type cachestatCollector struct {
+	paths                             []string
+	cached, evicted, dirty, writeback typedDesc
+	logger                            log.Logger
+}
+
+func init() {
+	registerCollector("cachestat", defaultDisabled, NewCachestatCollector)
+}
+
+// NewCachestatCollector returns a new Collector exposing page cache stats.
+func NewCachestatCollector(logger log.Logger) (Collector, error) {
+	var paths []string
+	for _, p := range strings.Split(*cachestatPaths, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	labels := []string{"mount"}
+	return &cachestatCollector{
+		paths: paths,
+		cached: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, pagecacheSubsystem, "cached_pages"),
+			"Number of pages of the path in the page cache.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		evicted: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, pagecacheSubsystem, "evicted_pages"),
+			"Number of pages of the path evicted from the page cache.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		dirty: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, pagecacheSubsystem, "dirty_pages"),
+			"Number of dirty pages of the path in the page cache.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		writeback: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, pagecacheSubsystem, "writeback_pages"),
+			"Number of pages of the path in the page cache marked for writeback.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. cachestat(2) is available on Linux 6.5+ only,
+// on older kernels the meminfo collector provides the system wide numbers.
+// The configured paths are relative to --path.rootfs, but get reported as
+// given.
+func (c *cachestatCollector) Update(ch chan<- prometheus.Metric) error {
+	nr, ok := cachestatSyscalls[runtime.GOARCH]
+	if !ok {
+		level.Debug(c.logger).Log("msg", "cachestat(2) not supported on this architecture", "arch", runtime.GOARCH)
+		return ErrNoData
+	}
+	found := false
+	for _, path := range c.paths {
+		file := rootfsFilePath(path)
+		var fs unix.Statfs_t
+		if err := unix.Statfs(file, &fs); err != nil {
+			level.Debug(c.logger).Log("msg", "statfs failed", "path", path, "err", err)
+			continue
+		}
+		if cachestatPseudoFS[uint32(fs.Type)] {
+			level.Debug(c.logger).Log("msg", "Skipping pseudo filesystem", "path", path)
+			continue
+		}
+		cs, err := getCachestat(nr, file)
+		if errors.Is(err, syscall.ENOSYS) {
+			level.Debug(c.logger).Log("msg", "cachestat(2) not supported, Linux < 6.5?")
+			return ErrNoData
+		}
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "cachestat(2) failed", "path", path, "err", err)
+			continue
+		}
+		ch <- c.cached.mustNewConstMetric(float64(cs.cache), path)
+		ch <- c.evicted.mustNewConstMetric(float64(cs.evicted), path)
+		ch <- c.dirty.mustNewConstMetric(float64(cs.dirty), path)
+		ch <- c.writeback.mustNewConstMetric(float64(cs.writeback), path)
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No paths to query via cachestat(2)")
+		return ErrNoData
+	}
+	return nil
+}
+
+func getCachestat(nr uintptr, path string) (*cachestat, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		cr cachestatRange // len 0 means up to the end of the file
+		cs cachestat
+	)
+	_, _, errno := syscall.Syscall6(nr, file.Fd(),
+		uintptr(unsafe.Pointer(&cr)), uintptr(unsafe.Pointer(&cs)), 0, 0, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return &cs, nil
+}
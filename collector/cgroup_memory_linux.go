@@ -0,0 +1,149 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocgroup_memory
+// +build !nocgroup_memory
+
+package collector
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var cgroupMemoryMaxDepth = kingpin.Flag("collector.cgroup-memory.max-depth", "Max. depth of the cgroup hierarchy to walk, 0 means the root cgroup only.").Default("2").Int()
+
+// cgroupMemoryFiles names the files and memory.stat keys of a cgroup version.
+type cgroupMemoryFiles struct {
+	usage, limit, cache, rss string
+}
+
+var (
+	cgroupMemoryV1 = cgroupMemoryFiles{"memory.usage_in_bytes", "memory.limit_in_bytes", "cache", "rss"}
+	cgroupMemoryV2 = cgroupMemoryFiles{"memory.current", "memory.max", "file", "anon"}
+)
+
+type cgroupMemoryCollector struct {
+	usage, limit, cache, rss typedDesc
+	logger                   log.Logger
+}
+
+func init() {
+	registerCollector("cgroup_memory", defaultDisabled, NewCgroupMemoryCollector)
+}
+
+// NewCgroupMemoryCollector returns a new Collector exposing the memory
+// accounting of cgroups.
+func NewCgroupMemoryCollector(logger log.Logger) (Collector, error) {
+	labels := []string{"cgroup"}
+	return &cgroupMemoryCollector{
+		usage: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cgroup_memory", "usage_bytes"),
+			"Memory currently used by the cgroup incl. its descendants.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		limit: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cgroup_memory", "limit_bytes"),
+			"Memory usage hard limit of the cgroup.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		cache: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cgroup_memory", "cache_bytes"),
+			"Page cache memory used by the cgroup.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		rss: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cgroup_memory", "rss_bytes"),
+			"Anonymous memory used by the cgroup.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. If the cgroup v1 memory controller is not
+// mounted, the cgroup v2 unified hierarchy gets used.
+func (c *cgroupMemoryCollector) Update(ch chan<- prometheus.Metric) error {
+	root, files := sysFilePath("fs/cgroup/memory"), cgroupMemoryV1
+	if _, err := os.Stat(root); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		root, files = sysFilePath("fs/cgroup"), cgroupMemoryV2
+		if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+			level.Debug(c.logger).Log("msg", "Neither cgroup v1 memory controller nor cgroup v2 found")
+			return ErrNoData
+		}
+	}
+	return c.walk(ch, root, "/", files, 0)
+}
+
+func (c *cgroupMemoryCollector) walk(ch chan<- prometheus.Metric, dir, cgroup string, files cgroupMemoryFiles, depth int) error {
+	c.updateCgroup(ch, dir, cgroup, files)
+	if depth >= *cgroupMemoryMaxDepth {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		// the cgroup may have gone in the meantime
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Skipping removed cgroup", "cgroup", cgroup)
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err := c.walk(ch, filepath.Join(dir, entry.Name()), filepath.Join(cgroup, entry.Name()), files, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateCgroup exposes the available memory stats of the given cgroup, e.g.
+// the cgroup v2 root has no memory.current and "max" limits mean unlimited.
+func (c *cgroupMemoryCollector) updateCgroup(ch chan<- prometheus.Metric, dir, cgroup string, files cgroupMemoryFiles) {
+	if v, err := readUintFromFile(filepath.Join(dir, files.usage)); err == nil {
+		ch <- c.usage.mustNewConstMetric(float64(v), cgroup)
+	}
+	if v, err := readUintFromFile(filepath.Join(dir, files.limit)); err == nil {
+		ch <- c.limit.mustNewConstMetric(float64(v), cgroup)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "memory.stat"))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Failed to parse memory.stat", "cgroup", cgroup, "err", err)
+		return
+	}
+	if v, ok := stats[files.cache]; ok {
+		ch <- c.cache.mustNewConstMetric(float64(v), cgroup)
+	}
+	if v, ok := stats[files.rss]; ok {
+		ch <- c.rss.mustNewConstMetric(float64(v), cgroup)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocgroup_memory
+// +build !nocgroup_memory
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCgroupMemoryCollector(t *testing.T) {
+	*cgroupMemoryMaxDepth = 2
+	for _, tc := range []struct {
+		version, want string
+	}{
+		{"v1", `# HELP node_cgroup_memory_cache_bytes Page cache memory used by the cgroup.
+# TYPE node_cgroup_memory_cache_bytes gauge
+node_cgroup_memory_cache_bytes{cgroup="/"} 2.147483648e+09
+node_cgroup_memory_cache_bytes{cgroup="/system.slice"} 2.68435456e+08
+node_cgroup_memory_cache_bytes{cgroup="/system.slice/sshd.service"} 4.194304e+06
+# HELP node_cgroup_memory_limit_bytes Memory usage hard limit of the cgroup.
+# TYPE node_cgroup_memory_limit_bytes gauge
+node_cgroup_memory_limit_bytes{cgroup="/"} 9.223372036854772e+18
+node_cgroup_memory_limit_bytes{cgroup="/system.slice"} 9.223372036854772e+18
+node_cgroup_memory_limit_bytes{cgroup="/system.slice/sshd.service"} 1.073741824e+09
+# HELP node_cgroup_memory_rss_bytes Anonymous memory used by the cgroup.
+# TYPE node_cgroup_memory_rss_bytes gauge
+node_cgroup_memory_rss_bytes{cgroup="/"} 1.073741824e+09
+node_cgroup_memory_rss_bytes{cgroup="/system.slice"} 1.34217728e+08
+node_cgroup_memory_rss_bytes{cgroup="/system.slice/sshd.service"} 2.097152e+06
+# HELP node_cgroup_memory_usage_bytes Memory currently used by the cgroup incl. its descendants.
+# TYPE node_cgroup_memory_usage_bytes gauge
+node_cgroup_memory_usage_bytes{cgroup="/"} 4.294967296e+09
+node_cgroup_memory_usage_bytes{cgroup="/system.slice"} 5.36870912e+08
+node_cgroup_memory_usage_bytes{cgroup="/system.slice/sshd.service"} 8.388608e+06
+`},
+		{"v2", `# HELP node_cgroup_memory_cache_bytes Page cache memory used by the cgroup.
+# TYPE node_cgroup_memory_cache_bytes gauge
+node_cgroup_memory_cache_bytes{cgroup="/"} 2.147483648e+09
+node_cgroup_memory_cache_bytes{cgroup="/system.slice"} 2.68435456e+08
+node_cgroup_memory_cache_bytes{cgroup="/user.slice"} 4.194304e+06
+# HELP node_cgroup_memory_limit_bytes Memory usage hard limit of the cgroup.
+# TYPE node_cgroup_memory_limit_bytes gauge
+node_cgroup_memory_limit_bytes{cgroup="/user.slice"} 1.073741824e+09
+# HELP node_cgroup_memory_rss_bytes Anonymous memory used by the cgroup.
+# TYPE node_cgroup_memory_rss_bytes gauge
+node_cgroup_memory_rss_bytes{cgroup="/"} 1.073741824e+09
+node_cgroup_memory_rss_bytes{cgroup="/system.slice"} 1.34217728e+08
+node_cgroup_memory_rss_bytes{cgroup="/user.slice"} 8.388608e+06
+# HELP node_cgroup_memory_usage_bytes Memory currently used by the cgroup incl. its descendants.
+# TYPE node_cgroup_memory_usage_bytes gauge
+node_cgroup_memory_usage_bytes{cgroup="/system.slice"} 5.36870912e+08
+node_cgroup_memory_usage_bytes{cgroup="/user.slice"} 1.6777216e+07
+`},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			*sysPath = "fixtures/cgroup_memory/" + tc.version
+			c, err := NewCgroupMemoryCollector(log.NewNopLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(tc.want)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCgroupMemoryRemovedCgroup(t *testing.T) {
+	*cgroupMemoryMaxDepth = 2
+	c := &cgroupMemoryCollector{logger: log.NewNopLogger()}
+	ch := make(chan prometheus.Metric)
+	if err := c.walk(ch, "fixtures/cgroup_memory/v1/fs/cgroup/memory/gone", "/gone", cgroupMemoryV1, 0); err != nil {
+		t.Errorf("removed cgroup must be skipped, got %v", err)
+	}
+}
@@ -19,6 +19,8 @@ package collector
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -44,6 +46,18 @@ var (
 		[]string{"collector"},
 		nil,
 	)
+	descConflictDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "collector", "desc_conflict_total"),
+		"node_exporter: Number of metrics dropped, because another collector already exposes a metric with the same name.",
+		[]string{"collector1", "collector2", "metric_name"},
+		nil,
+	)
+	initErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "collector", "init_error"),
+		"node_exporter: Whether a lazily initialized collector failed to initialize.",
+		[]string{"collector"},
+		nil,
+	)
 )
 
 const (
@@ -57,6 +71,7 @@ var (
 	initiatedCollectors    = make(map[string]Collector)
 	collectorState         = make(map[string]*bool)
 	forcedCollectors       = map[string]bool{} // collectors which have been explicitly enabled or disabled
+	cumulativeFlags        []*[]string         // repeatable flags, which need to be cleared before re-parsing
 )
 
 func registerCollector(collector string, isDefaultEnabled bool, factory func(logger log.Logger) (Collector, error)) {
@@ -77,10 +92,92 @@ func registerCollector(collector string, isDefaultEnabled bool, factory func(log
 	factories[collector] = factory
 }
 
+// registerLazyCollector registers a collector, which is expensive to
+// initialize. Its factory gets called on the first Update, only.
+func registerLazyCollector(collector string, isDefaultEnabled bool, factory func(logger log.Logger) (Collector, error)) {
+	registerCollector(collector, isDefaultEnabled, func(logger log.Logger) (Collector, error) {
+		return &LazyCollector{name: collector, factory: factory, logger: logger}, nil
+	})
+}
+
+// Backoff limits for retrying the creation of a LazyCollector.
+const (
+	lazyInitBackoffMin = 10 * time.Second
+	lazyInitBackoffMax = 30 * time.Minute
+)
+
+// LazyCollector defers the creation of the wrapped collector until its
+// first Update. If the creation fails, it gets retried on later updates with
+// an exponential backoff. Constructor errors get exposed via
+// node_collector_init_error.
+type LazyCollector struct {
+	name    string
+	factory func(logger log.Logger) (Collector, error)
+	logger  log.Logger
+	mtx     sync.Mutex
+	c       Collector
+	next    time.Time
+	backoff time.Duration
+}
+
+// collector returns the wrapped collector, or nil if it could not be created
+// (yet).
+func (l *LazyCollector) collector() Collector {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.c != nil || time.Now().Before(l.next) {
+		return l.c
+	}
+	c, err := l.factory(l.logger)
+	if err != nil {
+		if l.backoff == 0 {
+			l.backoff = lazyInitBackoffMin
+		} else if l.backoff *= 2; l.backoff > lazyInitBackoffMax {
+			l.backoff = lazyInitBackoffMax
+		}
+		l.next = time.Now().Add(l.backoff)
+		level.Error(l.logger).Log("msg", "collector initialization failed", "retry_in", l.backoff, "err", err)
+		return nil
+	}
+	l.c = c
+	return c
+}
+
+// Update implements Collector.
+func (l *LazyCollector) Update(ch chan<- prometheus.Metric) error {
+	c := l.collector()
+	if c == nil {
+		return ErrNoData
+	}
+	return c.Update(ch)
+}
+
+// Close implements closer.
+func (l *LazyCollector) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if c, ok := l.c.(closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// initError returns 1 if the wrapped collector could not be created, 0
+// otherwise.
+func (l *LazyCollector) initError() float64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.c == nil {
+		return 1
+	}
+	return 0
+}
+
 // NodeCollector implements the prometheus.Collector interface.
 type NodeCollector struct {
 	Collectors map[string]Collector
 	logger     log.Logger
+	descs      *descTracker
 }
 
 // DisableDefaultCollectors sets the collector state to false for all collectors which
@@ -105,6 +202,105 @@ func collectorFlagAction(collector string) func(ctx *kingpin.ParseContext) error
 	}
 }
 
+// cumulativeFlag registers the given repeatable flag value, so that
+// ReloadFlags is able to clear it before the command line gets re-parsed.
+func cumulativeFlag(value *[]string) *[]string {
+	cumulativeFlags = append(cumulativeFlags, value)
+	return value
+}
+
+// flagCollector returns the name of the collector the given collector.*
+// flag belongs to. Collector names may contain dots, so the longest matching
+// name wins, e.g. collector.a.b.x belongs to a collector named a.b, not to a.
+func flagCollector(flag string) (string, bool) {
+	name := strings.TrimPrefix(flag, "collector.")
+	collector := ""
+	for c := range factories {
+		if len(c) > len(collector) && (name == c || strings.HasPrefix(name, c+".")) {
+			collector = c
+		}
+	}
+	return collector, collector != ""
+}
+
+// ReloadFlags parses the given command line again, which re-reads @file
+// arguments, and drops the initiated collectors whose flags changed, so that
+// the next NewNodeCollector call re-creates them. Dropped collectors, which
+// implement closer, get closed. All other collectors keep their state.
+// Changed --path.* flags or flags of unknown collectors affect all
+// collectors. It returns the names of changed flags, which do not belong to
+// a collector, i.e. which need to be handled by the caller. If parsing fails,
+// all flags keep their previous value. The caller must ensure, that no
+// collector runs concurrently.
+func ReloadFlags(app *kingpin.Application, args []string, logger log.Logger) ([]string, error) {
+	if _, err := app.ParseContext(args); err != nil {
+		return nil, err
+	}
+	flags := app.Model().Flags
+	old := make(map[string]string, len(flags))
+	for _, f := range flags {
+		old[f.Name] = f.String()
+	}
+	oldCumulative := make([][]string, len(cumulativeFlags))
+	for i, v := range cumulativeFlags {
+		oldCumulative[i] = *v
+		*v = nil
+	}
+	oldForced := forcedCollectors
+	forcedCollectors = map[string]bool{}
+
+	if _, err := app.Parse(args); err != nil {
+		for _, f := range flags {
+			if s := old[f.Name]; f.String() != s {
+				f.Value.Set(s)
+			}
+		}
+		for i, v := range cumulativeFlags {
+			*v = oldCumulative[i]
+		}
+		forcedCollectors = oldForced
+		return nil, err
+	}
+
+	all := false
+	changed := make(map[string]bool)
+	var other []string
+	for _, f := range flags {
+		if f.String() == old[f.Name] {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(f.Name, "path."):
+			all = true
+		case strings.HasPrefix(f.Name, "collector."):
+			if name, ok := flagCollector(f.Name); ok {
+				changed[name] = true
+			} else if strings.Contains(strings.TrimPrefix(f.Name, "collector."), ".") {
+				all = true
+			} else {
+				other = append(other, f.Name)
+			}
+		default:
+			other = append(other, f.Name)
+		}
+	}
+
+	initiatedCollectorsMtx.Lock()
+	defer initiatedCollectorsMtx.Unlock()
+	for name, c := range initiatedCollectors {
+		if !all && !changed[name] {
+			continue
+		}
+		if c, ok := c.(closer); ok {
+			if err := c.Close(); err != nil {
+				level.Warn(logger).Log("msg", "failed to close collector", "collector", name, "err", err)
+			}
+		}
+		delete(initiatedCollectors, name)
+	}
+	return other, nil
+}
+
 // NewNodeCollector creates a new NodeCollector.
 func NewNodeCollector(logger log.Logger, filters ...string) (*NodeCollector, error) {
 	f := make(map[string]bool)
@@ -136,34 +332,61 @@ func NewNodeCollector(logger log.Logger, filters ...string) (*NodeCollector, err
 			initiatedCollectors[key] = collector
 		}
 	}
-	return &NodeCollector{Collectors: collectors, logger: logger}, nil
+	return &NodeCollector{Collectors: collectors, logger: logger, descs: newDescTracker(logger)}, nil
 }
 
 // Describe implements the prometheus.Collector interface.
 func (n NodeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- scrapeDurationDesc
 	ch <- scrapeSuccessDesc
+	ch <- descConflictDesc
+	ch <- initErrorDesc
 }
 
-// Collect implements the prometheus.Collector interface.
+// Collect implements the prometheus.Collector interface. The metrics of the
+// collectors get buffered and passed to the descTracker in the order of the
+// collector names, so that metric name conflicts get resolved the same way
+// on each scrape.
 func (n NodeCollector) Collect(ch chan<- prometheus.Metric) {
 	begin := time.Now()
+	names := make([]string, 0, len(n.Collectors))
+	for name := range n.Collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([][]prometheus.Metric, len(names))
 	wg := sync.WaitGroup{}
-	wg.Add(len(n.Collectors))
-	for name, c := range n.Collectors {
-		go func(name string, c Collector) {
-			execute(name, c, ch, n.logger)
+	wg.Add(len(names))
+	for i, name := range names {
+		go func(i int, name string, c Collector) {
+			metrics[i] = execute(name, c, ch, n.logger)
 			wg.Done()
-		}(name, c)
+		}(i, name, n.Collectors[name])
 	}
 	wg.Wait()
+	n.descs.forward(names, metrics, ch)
+	n.descs.collect(ch)
 	duration := time.Since(begin)
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds(), "overall")
 }
 
-func execute(name string, c Collector, ch chan<- prometheus.Metric, logger log.Logger) {
+// execute runs the Update of the given collector and returns the metrics it
+// produced. Its scrape duration, success and the init error of lazy
+// collectors get sent to ch directly, i.e. bypass the descTracker.
+func execute(name string, c Collector, ch chan<- prometheus.Metric, logger log.Logger) []prometheus.Metric {
 	begin := time.Now()
-	err := c.Update(ch)
+	var metrics []prometheus.Metric
+	updateCh := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		for m := range updateCh {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+	err := c.Update(updateCh)
+	close(updateCh)
+	<-done
 	duration := time.Since(begin)
 	var success float64
 
@@ -180,6 +403,10 @@ func execute(name string, c Collector, ch chan<- prometheus.Metric, logger log.L
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds(), name)
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, name)
+	if l, ok := c.(*LazyCollector); ok {
+		ch <- prometheus.MustNewConstMetric(initErrorDesc, prometheus.GaugeValue, l.initError(), name)
+	}
+	return metrics
 }
 
 // Collector is the interface a collector has to implement.
@@ -188,6 +415,13 @@ type Collector interface {
 	Update(ch chan<- prometheus.Metric) error
 }
 
+// closer is implemented by collectors, which hold resources like file
+// descriptors, sockets or goroutines, which need to be released when the
+// collector gets dropped.
+type closer interface {
+	Close() error
+}
+
 type typedDesc struct {
 	desc      *prometheus.Desc
 	valueType prometheus.ValueType
@@ -197,6 +431,93 @@ func (d *typedDesc) mustNewConstMetric(value float64, labels ...string) promethe
 	return prometheus.MustNewConstMetric(d.desc, d.valueType, value, labels...)
 }
 
+// descTracker drops metrics of collectors using a metric name, which another
+// collector already exposed in the same scrape, and counts these conflicts.
+// The collector with the alphabetically first name owns a metric name. The
+// ownership gets determined on each scrape, so a collector, which stops
+// exposing a metric, does not block it forever. Conflicts cannot be detected
+// on startup: Collector has no Describe, and many collectors derive their
+// metric names from the data they find on Update.
+type descTracker struct {
+	mtx       sync.Mutex
+	conflicts map[[3]string]uint64
+	logger    log.Logger
+}
+
+func newDescTracker(logger log.Logger) *descTracker {
+	return &descTracker{
+		conflicts: make(map[[3]string]uint64),
+		logger:    logger,
+	}
+}
+
+// forward sends the metrics of the named collectors of a scrape to out, if
+// the collector owns their metric names. names must be sorted and metrics[i]
+// must contain the metrics of the collector names[i].
+func (t *descTracker) forward(names []string, metrics [][]prometheus.Metric, out chan<- prometheus.Metric) {
+	owners := make(map[string]string)
+	for i, name := range names {
+		for _, m := range metrics[i] {
+			fqName := m.Desc().FQName()
+			owner, ok := owners[fqName]
+			if !ok {
+				owners[fqName] = name
+				owner = name
+			}
+			if owner == name {
+				out <- m
+				continue
+			}
+			t.conflict(owner, name, fqName)
+		}
+	}
+}
+
+// conflict counts a metric of the named collector dropped in favor of the
+// owner's one.
+func (t *descTracker) conflict(owner, name, fqName string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	key := [3]string{owner, name, fqName}
+	if t.conflicts[key] == 0 {
+		level.Warn(t.logger).Log("msg", "dropping metric, which is already exposed by another collector", "metric_name", fqName, "collector", name, "owner", owner)
+	}
+	t.conflicts[key]++
+}
+
+func (t *descTracker) collect(ch chan<- prometheus.Metric) {
+	t.mtx.Lock()
+	metrics := make([]prometheus.Metric, 0, len(t.conflicts))
+	for key, count := range t.conflicts {
+		metrics = append(metrics, prometheus.MustNewConstMetric(descConflictDesc, prometheus.CounterValue, float64(count), key[0], key[1], key[2]))
+	}
+	t.mtx.Unlock()
+	for _, m := range metrics {
+		ch <- m
+	}
+}
+
+// SafeRegistry is a prometheus.Registry, whose MustRegister logs errors like
+// duplicate registrations instead of panicking.
+type SafeRegistry struct {
+	*prometheus.Registry
+	logger log.Logger
+}
+
+// NewSafeRegistry returns a new SafeRegistry.
+func NewSafeRegistry(logger log.Logger) *SafeRegistry {
+	return &SafeRegistry{Registry: prometheus.NewRegistry(), logger: logger}
+}
+
+// MustRegister implements prometheus.Registerer.
+func (r *SafeRegistry) MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			level.Error(r.logger).Log("msg", "failed to register collector", "err", err)
+		}
+	}
+}
+
 // ErrNoData indicates the collector found no data to collect, but had no other error.
 var ErrNoData = errors.New("collector returned no data")
 
@@ -0,0 +1,283 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+type testCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c testCollector) Update(ch chan<- prometheus.Metric) error {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+	return nil
+}
+
+// updateCollector adapts a Collector to a prometheus.Collector, e.g. for
+// testutil.CollectAndCompare.
+type updateCollector struct {
+	Collector
+}
+
+func (c updateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Update(ch)
+}
+
+func (c updateCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func TestDescConflict(t *testing.T) {
+	newDesc := func(help string) *prometheus.Desc {
+		return prometheus.NewDesc("node_test_info", help, nil, nil)
+	}
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"b": testCollector{newDesc("b")},
+			"a": testCollector{newDesc("a")},
+		},
+		logger: log.NewNopLogger(),
+		descs:  newDescTracker(log.NewNopLogger()),
+	}
+	for i := 1; i <= 10; i++ {
+		infos, conflicts := collectDescConflicts(t, nc)
+		if infos != 1 {
+			t.Errorf("want 1 node_test_info metric, got %d", infos)
+		}
+		if len(conflicts) != 1 {
+			t.Fatalf("want 1 conflict metric, got %d", len(conflicts))
+		}
+		want := map[string]string{"collector1": "a", "collector2": "b", "metric_name": "node_test_info"}
+		for _, l := range conflicts[0].GetLabel() {
+			if want[l.GetName()] != l.GetValue() {
+				t.Errorf("want label %s=%q, got %q", l.GetName(), want[l.GetName()], l.GetValue())
+			}
+		}
+		if got := conflicts[0].GetCounter().GetValue(); got != float64(i) {
+			t.Errorf("want %d conflicts, got %f", i, got)
+		}
+	}
+}
+
+func TestDescConflictOwnership(t *testing.T) {
+	desc := prometheus.NewDesc("node_test_info", "b", nil, nil)
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"a": testCollector{prometheus.NewDesc("node_test_info", "a", nil, nil)},
+			"b": testCollector{desc},
+		},
+		logger: log.NewNopLogger(),
+		descs:  newDescTracker(log.NewNopLogger()),
+	}
+	collectDescConflicts(t, nc)
+	// once a stops exposing the metric, the one of b must not be dropped
+	nc.Collectors["a"] = testCollector{prometheus.NewDesc("node_test_other_info", "a", nil, nil)}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		nc.Collect(ch)
+		close(ch)
+	}()
+	found := false
+	for m := range ch {
+		if m.Desc() == desc {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("metric of collector b should have been exposed")
+	}
+}
+
+// collectDescConflicts runs a scrape and returns the number of node_test_info
+// metrics of collector a and the desc conflict metrics.
+func collectDescConflicts(t *testing.T, nc *NodeCollector) (int, []*dto.Metric) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		nc.Collect(ch)
+		close(ch)
+	}()
+
+	var infos int
+	var conflicts []*dto.Metric
+	for m := range ch {
+		switch m.Desc() {
+		case descConflictDesc:
+			pb := &dto.Metric{}
+			if err := m.Write(pb); err != nil {
+				t.Fatal(err)
+			}
+			conflicts = append(conflicts, pb)
+		case scrapeDurationDesc, scrapeSuccessDesc:
+		default:
+			if got := m.Desc().FQName(); got != "node_test_info" {
+				t.Errorf("want metric node_test_info, got %s", got)
+			}
+			if m.Desc() != nc.Collectors["a"].(testCollector).desc {
+				t.Error("metric of collector b should have been dropped")
+			}
+			infos++
+		}
+	}
+	return infos, conflicts
+}
+
+func TestLazyCollector(t *testing.T) {
+	fail := true
+	factory := func(log.Logger) (Collector, error) {
+		if fail {
+			return nil, errors.New("not yet")
+		}
+		return testCollector{prometheus.NewDesc("node_test_info", "test", nil, nil)}, nil
+	}
+	nc := &NodeCollector{
+		Collectors: map[string]Collector{
+			"x": &LazyCollector{name: "x", factory: factory, logger: log.NewNopLogger()},
+			"y": &LazyCollector{name: "y", factory: factory, logger: log.NewNopLogger()},
+		},
+		logger: log.NewNopLogger(),
+		descs:  newDescTracker(log.NewNopLogger()),
+	}
+	initErrors := func() map[string]float64 {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			nc.Collect(ch)
+			close(ch)
+		}()
+		got := map[string]float64{}
+		for m := range ch {
+			if m.Desc() != initErrorDesc {
+				continue
+			}
+			pb := &dto.Metric{}
+			if err := m.Write(pb); err != nil {
+				t.Fatal(err)
+			}
+			got[pb.GetLabel()[0].GetValue()] = pb.GetGauge().GetValue()
+		}
+		return got
+	}
+
+	if got, want := initErrors(), map[string]float64{"x": 1, "y": 1}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want init errors %v, got %v", want, got)
+	}
+	fail = false
+	// still within the backoff period
+	if got, want := initErrors(), map[string]float64{"x": 1, "y": 1}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want init errors %v, got %v", want, got)
+	}
+	nc.Collectors["x"].(*LazyCollector).next = time.Time{}
+	if got, want := initErrors(), map[string]float64{"x": 0, "y": 1}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want init errors %v, got %v", want, got)
+	}
+}
+
+type closeCollector struct {
+	closed bool
+}
+
+func (c *closeCollector) Update(ch chan<- prometheus.Metric) error {
+	return nil
+}
+
+func (c *closeCollector) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestReloadFlags(t *testing.T) {
+	oldFactories, oldInitiated, oldCumulative, oldForced := factories, initiatedCollectors, cumulativeFlags, forcedCollectors
+	defer func() {
+		factories, initiatedCollectors, cumulativeFlags, forcedCollectors = oldFactories, oldInitiated, oldCumulative, oldForced
+	}()
+	factories = map[string]func(log.Logger) (Collector, error){"cpu": nil, "nfs": nil, "nfs.mountstats": nil}
+	all := []string{"cpu", "nfs", "nfs.mountstats"}
+	base := []string{"--collector.nfs.tags=a"}
+
+	for _, tc := range []struct {
+		name    string
+		args    []string
+		dropped []string
+		other   []string
+		err     bool
+	}{
+		{name: "unchanged", args: base},
+		{name: "collector flag", args: append(base, "--collector.cpu.info"), dropped: []string{"cpu"}},
+		{name: "cumulative flag", args: []string{"--collector.nfs.tags=b"}, dropped: []string{"nfs"}},
+		{name: "dotted collector name", args: append(base, "--collector.nfs.mountstats.opt=x"), dropped: []string{"nfs.mountstats"}},
+		{name: "path flag", args: append(base, "--path.procfs=/host/proc"), dropped: all},
+		{name: "unknown collector", args: append(base, "--collector.foo.bar=x"), dropped: all},
+		{name: "other flag", args: append(base, "--web.x=y"), other: []string{"web.x"}},
+		{name: "invalid flag", args: append(base, "--no-such-flag"), err: true},
+		{name: "invalid value", args: append(base, "--collector.nfs.tags=b", "--web.x=y", "--collector.nfs.num=abc"), err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := kingpin.New("test", "")
+			app.Flag("collector.cpu.info", "").Bool()
+			num := app.Flag("collector.nfs.num", "").Default("1").Int()
+			tags := app.Flag("collector.nfs.tags", "").Strings()
+			app.Flag("collector.nfs.mountstats.opt", "").String()
+			app.Flag("collector.foo.bar", "").String()
+			app.Flag("path.procfs", "").Default("/proc").String()
+			web := app.Flag("web.x", "").String()
+			if _, err := app.Parse(base); err != nil {
+				t.Fatal(err)
+			}
+			cumulativeFlags = []*[]string{tags}
+			forcedCollectors = map[string]bool{}
+			collectors := map[string]*closeCollector{}
+			initiatedCollectors = map[string]Collector{}
+			for _, name := range all {
+				collectors[name] = &closeCollector{}
+				initiatedCollectors[name] = collectors[name]
+			}
+
+			other, err := ReloadFlags(app, tc.args, log.NewNopLogger())
+			if tc.err {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				if *num != 1 || *web != "" || !reflect.DeepEqual(*tags, []string{"a"}) {
+					t.Errorf("flags not restored: num=%d web.x=%q tags=%v", *num, *web, *tags)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.other, other) {
+				t.Errorf("want other flags %v, got %v", tc.other, other)
+			}
+			dropped := map[string]bool{}
+			for _, name := range tc.dropped {
+				dropped[name] = true
+			}
+			for _, name := range all {
+				if _, ok := initiatedCollectors[name]; ok == dropped[name] {
+					t.Errorf("collector %s: want dropped %v, got %v", name, dropped[name], !ok)
+				}
+				if collectors[name].closed != dropped[name] {
+					t.Errorf("collector %s: want closed %v, got %v", name, dropped[name], collectors[name].closed)
+				}
+			}
+		})
+	}
+}
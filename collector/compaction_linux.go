@@ -0,0 +1,84 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nothp_compaction
+// +build !nothp_compaction
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// compactionFields are the /proc/vmstat fields exposed by the
+// compactionCollector.
+var compactionFields = []string{
+	"compact_migrate_scanned",
+	"compact_free_scanned",
+	"compact_isolated",
+	"compact_stall",
+	"compact_fail",
+	"compact_success",
+	"compact_daemon_wake",
+	"compact_daemon_migrate_scanned",
+	"compact_daemon_free_scanned",
+}
+
+type compactionCollector struct {
+	counters map[string]typedDesc
+	logger   log.Logger
+}
+
+func init() {
+	registerCollector("thp_compaction", defaultDisabled, NewCompactionCollector)
+}
+
+// NewCompactionCollector returns a new Collector exposing memory compaction
+// statistics.
+func NewCompactionCollector(logger log.Logger) (Collector, error) {
+	counters := make(map[string]typedDesc, len(compactionFields))
+	for _, field := range compactionFields {
+		counters[field] = typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "compaction", strings.TrimPrefix(field, "compact_")+"_total"),
+			fmt.Sprintf("/proc/vmstat information field %s.", field),
+			nil, nil,
+		), prometheus.CounterValue}
+	}
+	return &compactionCollector{counters: counters, logger: logger}, nil
+}
+
+// Update implements Collector and exposes the compaction fields of
+// /proc/vmstat. Kernels without CONFIG_COMPACTION have none of them.
+func (c *compactionCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("vmstat"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for field, desc := range c.counters {
+		if value, ok := stats[field]; ok {
+			ch <- desc.mustNewConstMetric(float64(value))
+		}
+	}
+	return nil
+}
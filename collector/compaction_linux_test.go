@@ -0,0 +1,57 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nothp_compaction
+// +build !nothp_compaction
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCompactionCollector(t *testing.T) {
+	*procPath = "fixtures/proc"
+	c, err := NewCompactionCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fixture lacks the compact_daemon_* fields (Linux < 4.6), so they
+	// must not be exposed.
+	want := `# HELP node_compaction_fail_total /proc/vmstat information field compact_fail.
+# TYPE node_compaction_fail_total counter
+node_compaction_fail_total 164840
+# HELP node_compaction_free_scanned_total /proc/vmstat information field compact_free_scanned.
+# TYPE node_compaction_free_scanned_total counter
+node_compaction_free_scanned_total 1.233662255e+10
+# HELP node_compaction_isolated_total /proc/vmstat information field compact_isolated.
+# TYPE node_compaction_isolated_total counter
+node_compaction_isolated_total 8.2707414e+07
+# HELP node_compaction_migrate_scanned_total /proc/vmstat information field compact_migrate_scanned.
+# TYPE node_compaction_migrate_scanned_total counter
+node_compaction_migrate_scanned_total 8.30267783e+08
+# HELP node_compaction_stall_total /proc/vmstat information field compact_stall.
+# TYPE node_compaction_stall_total counter
+node_compaction_stall_total 210959
+# HELP node_compaction_success_total /proc/vmstat information field compact_success.
+# TYPE node_compaction_success_total counter
+node_compaction_success_total 46119
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
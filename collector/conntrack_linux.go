@@ -17,14 +17,22 @@
 package collector
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	conntrackDetail = kingpin.Flag("collector.conntrack.detail", "Parse /proc/net/nf_conntrack and expose the number of entries per protocol. Expensive on hosts with large conntrack tables.").Default("false").Bool()
 )
 
 type conntrackCollector struct {
@@ -38,9 +46,15 @@ type conntrackCollector struct {
 	drop          *prometheus.Desc
 	earlyDrop     *prometheus.Desc
 	searchRestart *prometheus.Desc
+	protoEntries  *prometheus.Desc
+	established   *prometheus.Desc
 	logger        log.Logger
 }
 
+type conntrackProto struct {
+	family, protocol string
+}
+
 type conntrackStatistics struct {
 	found         uint64 // Number of searched entries which were successful
 	invalid       uint64 // Number of packets seen which can not be tracked
@@ -109,6 +123,16 @@ func NewConntrackCollector(logger log.Logger) (Collector, error) {
 			"Number of conntrack table lookups which had to be restarted due to hashtable resizes.",
 			nil, nil,
 		),
+		protoEntries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "proto_entries"),
+			"Number of connection tracking entries by protocol.",
+			[]string{"family", "protocol"}, nil,
+		),
+		established: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "established_entries"),
+			"Number of connection tracking entries of TCP connections in ESTABLISHED state.",
+			[]string{"family"}, nil,
+		),
 		logger: logger,
 	}, nil
 }
@@ -149,9 +173,61 @@ func (c *conntrackCollector) Update(ch chan<- prometheus.Metric) error {
 		c.earlyDrop, prometheus.GaugeValue, float64(conntrackStats.earlyDrop))
 	ch <- prometheus.MustNewConstMetric(
 		c.searchRestart, prometheus.GaugeValue, float64(conntrackStats.searchRestart))
+
+	if *conntrackDetail {
+		return c.updateDetail(ch)
+	}
 	return nil
 }
 
+func (c *conntrackCollector) updateDetail(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/nf_conntrack"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// nf_conntrack_procfs is not built in (CONFIG_NF_CONNTRACK_PROCFS=n)
+			level.Debug(c.logger).Log("msg", "Conntrack entries are not available", "err", err)
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	entries, established, err := parseConntrackEntries(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for proto, value := range entries {
+		ch <- prometheus.MustNewConstMetric(
+			c.protoEntries, prometheus.GaugeValue, float64(value), proto.family, proto.protocol)
+	}
+	for family, value := range established {
+		ch <- prometheus.MustNewConstMetric(
+			c.established, prometheus.GaugeValue, float64(value), family)
+	}
+	return nil
+}
+
+// parseConntrackEntries counts the lines of /proc/net/nf_conntrack per
+// family and protocol. Lines look like
+// "ipv4     2 tcp      6 431999 ESTABLISHED src=10.0.0.1 ...", the state
+// column exists for TCP entries only.
+func parseConntrackEntries(r io.Reader) (map[conntrackProto]uint64, map[string]uint64, error) {
+	entries := make(map[conntrackProto]uint64)
+	established := make(map[string]uint64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		entries[conntrackProto{fields[0], fields[2]}]++
+		if fields[2] == "tcp" && len(fields) > 5 && fields[5] == "ESTABLISHED" {
+			established[fields[0]]++
+		}
+	}
+	return entries, established, scanner.Err()
+}
+
 func (c *conntrackCollector) handleErr(err error) error {
 	if errors.Is(err, os.ErrNotExist) {
 		level.Debug(c.logger).Log("msg", "conntrack probably not loaded")
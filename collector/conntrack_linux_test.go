@@ -0,0 +1,45 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noconntrack
+// +build !noconntrack
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConntrackEntries(t *testing.T) {
+	entries, established, err := parseConntrackEntries(strings.NewReader(`ipv4     2 tcp      6 431999 ESTABLISHED src=10.0.0.1 dst=10.0.0.2 sport=22 dport=50000 src=10.0.0.2 dst=10.0.0.1 sport=50000 dport=22 [ASSURED] mark=0 zone=0 use=2
+ipv4     2 tcp      6 102 TIME_WAIT src=10.0.0.1 dst=10.0.0.3 sport=40000 dport=443 src=10.0.0.3 dst=10.0.0.1 sport=443 dport=40000 [ASSURED] mark=0 zone=0 use=2
+ipv4     2 udp      17 28 src=10.0.0.1 dst=10.0.0.53 sport=53000 dport=53 src=10.0.0.53 dst=10.0.0.1 sport=53 dport=53000 mark=0 zone=0 use=2
+ipv6     10 tcp      6 431999 ESTABLISHED src=fe80::1 dst=fe80::2 sport=22 dport=50001 src=fe80::2 dst=fe80::1 sport=50001 dport=22 [ASSURED] mark=0 zone=0 use=2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEntries := map[conntrackProto]uint64{
+		{"ipv4", "tcp"}: 2,
+		{"ipv4", "udp"}: 1,
+		{"ipv6", "tcp"}: 1,
+	}
+	if !reflect.DeepEqual(wantEntries, entries) {
+		t.Errorf("want entries %v, got %v", wantEntries, entries)
+	}
+	if want := map[string]uint64{"ipv4": 1, "ipv6": 1}; !reflect.DeepEqual(want, established) {
+		t.Errorf("want established %v, got %v", want, established)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocoredump
+// +build !nocoredump
+
+package collector
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const coredumpSubsystem = "coredump"
+
+type coredumpCollector struct {
+	patternInfo, usesPID, enabled, suidDumpable typedDesc
+	logger                                      log.Logger
+}
+
+func init() {
+	registerCollector("coredump", defaultDisabled, NewCoredumpCollector)
+}
+
+// NewCoredumpCollector returns a new Collector exposing the core dump
+// configuration.
+func NewCoredumpCollector(logger log.Logger) (Collector, error) {
+	return &coredumpCollector{
+		patternInfo: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, coredumpSubsystem, "pattern_info"),
+			"Content of /proc/sys/kernel/core_pattern, value is always 1.",
+			[]string{"pattern"}, nil,
+		), prometheus.GaugeValue},
+		usesPID: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, coredumpSubsystem, "uses_pid"),
+			"Whether the PID gets appended to the core dump file name (1) or not (0).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		enabled: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, coredumpSubsystem, "enabled"),
+			"Whether core dumps get written (1) or discarded by an empty or /dev/null pattern (0).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		suidDumpable: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, coredumpSubsystem, "suid_dumpable"),
+			"Core dump mode for setuid binaries: 0 (disabled), 1 (debug) or 2 (suidsafe).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the core dump related sysctls.
+func (c *coredumpCollector) Update(ch chan<- prometheus.Metric) error {
+	pattern, err := readStringFromFile(procFilePath("sys/kernel/core_pattern"))
+	if err != nil {
+		return err
+	}
+	ch <- c.patternInfo.mustNewConstMetric(1, pattern)
+	enabled := 1.0
+	if pattern == "" || pattern == "/dev/null" {
+		enabled = 0
+	}
+	ch <- c.enabled.mustNewConstMetric(enabled)
+
+	for _, sysctl := range []struct {
+		file string
+		desc typedDesc
+	}{
+		{"sys/kernel/core_uses_pid", c.usesPID},
+		{"sys/fs/suid_dumpable", c.suidDumpable},
+	} {
+		file := procFilePath(sysctl.file)
+		data, err := readStringFromFile(file)
+		if err != nil {
+			return err
+		}
+		value, err := strconv.ParseFloat(data, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value in %s: %w", file, err)
+		}
+		ch <- sysctl.desc.mustNewConstMetric(value)
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -38,6 +39,7 @@ type cpuCollector struct {
 	fs                 procfs.FS
 	cpu                *prometheus.Desc
 	cpuInfo            *prometheus.Desc
+	cpuStepping        *prometheus.Desc
 	cpuFlagsInfo       *prometheus.Desc
 	cpuBugsInfo        *prometheus.Desc
 	cpuGuest           *prometheus.Desc
@@ -46,10 +48,18 @@ type cpuCollector struct {
 	logger             log.Logger
 	cpuInfoLabels      []string
 	cpuInfoValues      []string
+	cpuSteppingValues  []float64
 	cpuFlagsInfoValues []string
 	cpuBugsInfoValues  []string
 	cpuStats           []procfs.CPUStat
 	cpuStatsMutex      sync.Mutex
+	enableStats        bool
+	enableGuest        bool
+
+	sfs          sysfs.FS
+	cpuInfoMutex sync.Mutex
+	cpuInfoRead  time.Time
+	cpuInfoStale bool
 
 	cpuFlagsIncludeRegexp *regexp.Regexp
 	cpuBugsIncludeRegexp  *regexp.Regexp
@@ -65,11 +75,12 @@ var (
 	enableThermThrottle  = kingpin.Flag("collector.cpu.throttle", "Enables metric cpu_seconds").Default("true").Bool()
 	flagsInclude         = kingpin.Flag("collector.cpu.info.flags-include", "Filter the `flags` field in cpuInfo with a value that must be a regular expression").String()
 	bugsInclude          = kingpin.Flag("collector.cpu.info.bugs-include", "Filter the `bugs` field in cpuInfo with a value that must be a regular expression").String()
+	infoRefresh          = kingpin.Flag("collector.cpu.info.refresh-interval", "Re-read /proc/cpuinfo and the cpufreq limits after this time, 0 means on CPU hotplug events only.").Default("0s").Duration()
 	jumpBackDebugMessage = fmt.Sprintf("CPU Idle counter jumped backwards more than %f seconds, possible hotplug event, resetting CPU stats", jumpBackSeconds)
 )
 
 func init() {
-	registerCollector("cpu", defaultEnabled, NewCPUCollector)
+	registerLazyCollector("cpu", defaultEnabled, NewCPUCollector)
 }
 
 // NewCPUCollector returns a new Collector exposing kernel/system statistics.
@@ -78,65 +89,135 @@ func NewCPUCollector(logger log.Logger) (Collector, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
-	info, err := fs.CPUInfo()
+	// pre-initialize collector vars
+	var cpuInfo, cpuStepping, cpuFlagsInfo, cpuBugsInfo, cpuGuest, cpuCoreThrottle, cpuPackageThrottle *prometheus.Desc
+	var flagsRegexp, bugsRegexp *regexp.Regexp
+	var sfs sysfs.FS
+	infoLabels := []string{ "package", "vendor", "family", "model", "model_name", "microcode", "stepping", "cachesize", "cores", "freq_base", "freq_max", "freq_min" }
+
+	if *flagsInclude != "" {
+		level.Info(logger).Log("msg", "flagsInclude", "cpu", *flagsInclude)
+		flagsRegexp, err = regexp.Compile(*flagsInclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile --collector.cpu.info.flags-include, the values of them must be regular expressions: %w", err)
+		}
+		cpuFlagsInfo = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "flag_info"),
+			"The `flags` field of CPU information from /proc/cpuinfo taken from the first core. Cached, see --collector.cpu.info.refresh-interval.",
+			[]string{"flag"}, nil,
+		)
+	}
+
+	if *bugsInclude != "" {
+		level.Info(logger).Log("msg", "bugsInclude", "cpu", *bugsInclude)
+		bugsRegexp, err = regexp.Compile(*bugsInclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile --collector.cpu.info.bugs-include, the values of them must be regular expressions: %w", err)
+		}
+		cpuBugsInfo = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "bug_info"),
+			"The `bugs` field of CPU information from /proc/cpuinfo taken from the first core. Cached, see --collector.cpu.info.refresh-interval.",
+			[]string{"bug"}, nil,
+		)
+	}
+
+	if *enableCPUInfo {
+		sfs, err = sysfs.NewFS(*sysPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sysfs: %w", err)
+		}
+		cpuInfo = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "info"),
+			"Cached /proc/cpuinfo and system/cpu/*/cpufreq/cpuinfo_{min,max}_freq per package, see --collector.cpu.info.refresh-interval.",
+			infoLabels, nil,
+		)
+		cpuStepping = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "stepping"),
+			"The `stepping` field of /proc/cpuinfo per package, -1 if not numeric.",
+			[]string{"package"}, nil,
+		)
+	}
+	if *enableStats && *enableCPUGuest {
+		cpuGuest = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "guest_seconds_total"),
+			"Seconds the CPUs spent in guests (VMs) for each mode.",
+			[]string{"cpu", "mode"}, nil,
+		)
+	}
+	if *enableThermThrottle {
+		cpuCoreThrottle = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "core_throttles_total"),
+			"Number of times this CPU core has been throttled.",
+			[]string{"package", "core"}, nil,
+		)
+		cpuPackageThrottle = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "package_throttles_total"),
+			"Number of times this CPU package has been throttled.",
+			[]string{"package"}, nil,
+		)
+	}
+
+	c := &cpuCollector{
+		fs:  fs,
+		sfs: sfs,
+		cpu: nodeCPUSecondsDesc,
+		cpuInfoLabels: infoLabels,
+		cpuInfo: cpuInfo,
+		cpuStepping: cpuStepping,
+		cpuFlagsInfo: cpuFlagsInfo,
+		cpuBugsInfo: cpuBugsInfo,
+		cpuGuest: cpuGuest,
+		cpuCoreThrottle: cpuCoreThrottle,
+		cpuPackageThrottle: cpuPackageThrottle,
+		logger: logger,
+		enableStats: *enableStats,
+		enableGuest: *enableStats && *enableCPUGuest,
+		cpuFlagsIncludeRegexp: flagsRegexp,
+		cpuBugsIncludeRegexp: bugsRegexp,
+	}
+	if err := c.readInfo(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// readInfo (re-)reads /proc/cpuinfo and the cpufreq limits into the cached
+// info values. The caller must hold cpuInfoMutex unless the collector is not
+// in use yet.
+func (c *cpuCollector) readInfo() error {
+	info, err := c.fs.CPUInfo()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get /proc/cpuinfo: %w", err)
+		return fmt.Errorf("failed to get /proc/cpuinfo: %w", err)
 	}
 
-	// pre-initialize collector vars
-	var cpuInfo, cpuFlagsInfo, cpuBugsInfo, cpuGuest, cpuCoreThrottle, cpuPackageThrottle *prometheus.Desc
+	var steppingValues []float64
 	flagValues := make([]string, 0)
 	bugValues := make([]string, 0)
-	infoLabels := []string{ "package", "vendor", "family", "model", "model_name", "microcode", "stepping", "cachesize", "cores", "freq_base", "freq_max", "freq_min" }
 	infoValues := make([]string, 0)
 
 	if len(info) != 0 {
 		cpu := info[0]
-		if *flagsInclude != "" {
-			level.Info(logger).Log("msg", "flagsInclude", "cpu", *flagsInclude)
-			regex, err := regexp.Compile(*flagsInclude)
-			if err != nil {
-				return nil, fmt.Errorf("failed to compile --collector.cpu.info.flags-include, the values of them must be regular expressions: %w", err)
-			}
+		if c.cpuFlagsIncludeRegexp != nil {
 			for _, val := range cpu.Flags {
-				if regex.MatchString(val) {
+				if c.cpuFlagsIncludeRegexp.MatchString(val) {
 					flagValues = append(flagValues, val)
 				}
 			}
-			cpuFlagsInfo = prometheus.NewDesc(
-				prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "flag_info"),
-				"The `flags` field of CPU information from /proc/cpuinfo taken from the first core. On change the collector needs to be restarted.",
-				[]string{"flag"}, nil,
-			)
 		}
-
-		if *bugsInclude != "" {
-			level.Info(logger).Log("msg", "bugsInclude", "cpu", *bugsInclude)
-			regex, err := regexp.Compile(*bugsInclude)
-			if err != nil {
-				return nil, fmt.Errorf("failed to compile --collector.cpu.info.bugs-include, the values of them must be regular expressions: %w", err)
-			}
+		if c.cpuBugsIncludeRegexp != nil {
 			for _, val := range cpu.Bugs {
-				if regex.MatchString(val) {
+				if c.cpuBugsIncludeRegexp.MatchString(val) {
 					bugValues = append(bugValues, val)
 				}
 			}
-			cpuBugsInfo = prometheus.NewDesc(
-				prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "bug_info"),
-				"The `bugs` field of CPU information from /proc/cpuinfo taken from the first core. On change the collector needs to be restarted.",
-				[]string{"bug"}, nil,
-			)
 		}
 	}
 
 	if *enableCPUInfo {
-		sfs, err := sysfs.NewFS(*sysPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open sysfs: %w", err)
-		}
-		cpuFreqs, err := sfs.SystemCpufreq()
+		cpuFreqs, err := c.sfs.SystemCpufreq()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get /sys/devices/system/cpu/cpu0/cpufreq/cpuinfo_*_freq: %w", err)
+			return fmt.Errorf("failed to get /sys/devices/system/cpu/cpu0/cpufreq/cpuinfo_*_freq: %w", err)
 		}
 		var seen uint64 = 0
 		var min, max, base, model string
@@ -155,7 +236,7 @@ func NewCPUCollector(logger log.Logger) (Collector, error) {
 						// TBD: scheinen vertauscht zu sein
 						min = strconv.FormatUint(*stats.CpuinfoMinimumFrequency,10) + "000"
 						max = strconv.FormatUint(*stats.CpuinfoMaximumFrequency,10) + "000"
-						base, err = sfs.SystemCpuBaseFrequency(stats.Name)
+						base, err = c.sfs.SystemCpuBaseFrequency(stats.Name)
 						break
 					}
 				}
@@ -188,50 +269,22 @@ func NewCPUCollector(logger log.Logger) (Collector, error) {
 			infoValues = append(infoValues, base)
 			infoValues = append(infoValues, max)
 			infoValues = append(infoValues, min)
+			stepping, err := strconv.Atoi(cpu.Stepping)
+			if err != nil {
+				level.Warn(c.logger).Log("msg", "Non-numeric CPU stepping, reporting -1", "package", cpu.PhysicalID, "stepping", cpu.Stepping)
+				stepping = -1
+			}
+			steppingValues = append(steppingValues, float64(stepping))
 		}
-		cpuInfo = prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "info"),
-			"Cached /proc/cpuinfo and system/cpu/*/cpufreq/cpuinfo_{min,max}_freq per package. On change the collector needs to be restarted.",
-			infoLabels, nil,
-		)
-	}
-	if *enableCPUGuest {
-		cpuGuest = prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "guest_seconds_total"),
-			"Seconds the CPUs spent in guests (VMs) for each mode.",
-			[]string{"cpu", "mode"}, nil,
-		)
-	}
-	if *enableThermThrottle {
-		cpuCoreThrottle = prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "core_throttles_total"),
-			"Number of times this CPU core has been throttled.",
-			[]string{"package", "core"}, nil,
-		)
-		cpuPackageThrottle = prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "package_throttles_total"),
-			"Number of times this CPU package has been throttled.",
-			[]string{"package"}, nil,
-		)
 	}
 
-	c := &cpuCollector{
-		fs:  fs,
-		cpu: nodeCPUSecondsDesc,
-		cpuInfoLabels: infoLabels,
-		cpuInfoValues: infoValues,
-		cpuFlagsInfoValues: flagValues,
-		cpuBugsInfoValues: bugValues,
-		cpuInfo: cpuInfo,
-		cpuFlagsInfo: cpuFlagsInfo,
-		cpuBugsInfo: cpuBugsInfo,
-		cpuGuest: cpuGuest,
-		cpuCoreThrottle: cpuCoreThrottle,
-		cpuPackageThrottle: cpuPackageThrottle,
-		logger: logger,
-	}
-
-	return c, nil
+	c.cpuInfoValues = infoValues
+	c.cpuSteppingValues = steppingValues
+	c.cpuFlagsInfoValues = flagValues
+	c.cpuBugsInfoValues = bugValues
+	c.cpuInfoRead = time.Now()
+	c.cpuInfoStale = false
+	return nil
 }
 
 // Update implements Collector and exposes cpu related metrics from /proc/stat and /sys/.../cpu/.
@@ -239,7 +292,7 @@ func (c *cpuCollector) Update(ch chan<- prometheus.Metric) error {
 	if err := c.updateInfo(ch); err != nil {
 		return err
 	}
-	if *enableStats {
+	if c.enableStats {
 		if err := c.updateStat(ch); err != nil {
 			return err
 		}
@@ -251,12 +304,27 @@ func (c *cpuCollector) Update(ch chan<- prometheus.Metric) error {
 }
 
 func (c *cpuCollector) updateInfo(ch chan<- prometheus.Metric) error {
+	c.cpuInfoMutex.Lock()
+	defer c.cpuInfoMutex.Unlock()
+	if c.cpuInfoStale || (*infoRefresh > 0 && time.Since(c.cpuInfoRead) >= *infoRefresh) {
+		level.Debug(c.logger).Log("msg", "Re-reading CPU info", "hotplug", c.cpuInfoStale)
+		// keep exposing the cached info on failure, cpuInfoStale and
+		// cpuInfoRead stay as they are, so the next scrape retries
+		if err := c.readInfo(); err != nil {
+			level.Warn(c.logger).Log("msg", "Failed to re-read CPU info, exposing cached info", "err", err)
+		}
+	}
+
 	last := len(c.cpuInfoValues)
 	if last != 0 {
 		k := len(c.cpuInfoLabels)
 		for i := 0; i < last; i += k {
 			ch <- prometheus.MustNewConstMetric(c.cpuInfo, prometheus.GaugeValue, 1, c.cpuInfoValues[i:i+k]...)
 		}
+		// the package is the first info label
+		for i, stepping := range c.cpuSteppingValues {
+			ch <- prometheus.MustNewConstMetric(c.cpuStepping, prometheus.GaugeValue, stepping, c.cpuInfoValues[i*k])
+		}
 	}
 
 	if len(c.cpuFlagsInfoValues) != 0 {
@@ -374,7 +442,7 @@ func (c *cpuCollector) updateStat(ch chan<- prometheus.Metric) error {
 		ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.CounterValue, cpuStat.SoftIRQ, cpuNum, "softirq")
 		ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.CounterValue, cpuStat.Steal, cpuNum, "steal")
 
-		if *enableCPUGuest {
+		if c.enableGuest {
 			// Guest CPU is also accounted for in cpuStat.User and cpuStat.Nice, expose these as separate metrics.
 			ch <- prometheus.MustNewConstMetric(c.cpuGuest, prometheus.CounterValue, cpuStat.Guest, cpuNum, "user")
 			ch <- prometheus.MustNewConstMetric(c.cpuGuest, prometheus.CounterValue, cpuStat.GuestNice, cpuNum, "nice")
@@ -384,6 +452,14 @@ func (c *cpuCollector) updateStat(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
+// markInfoStale makes the next updateInfo re-read the CPU info, e.g. after
+// a CPU hotplug event.
+func (c *cpuCollector) markInfoStale() {
+	c.cpuInfoMutex.Lock()
+	c.cpuInfoStale = true
+	c.cpuInfoMutex.Unlock()
+}
+
 // updateCPUStats updates the internal cache of CPU stats.
 func (c *cpuCollector) updateCPUStats(newStats []procfs.CPUStat) {
 
@@ -393,6 +469,9 @@ func (c *cpuCollector) updateCPUStats(newStats []procfs.CPUStat) {
 
 	// Reset the cache if the list of CPUs has changed.
 	if len(c.cpuStats) != len(newStats) {
+		if c.cpuStats != nil {
+			c.markInfoStale()
+		}
 		c.cpuStats = make([]procfs.CPUStat, len(newStats))
 	}
 
@@ -401,6 +480,7 @@ func (c *cpuCollector) updateCPUStats(newStats []procfs.CPUStat) {
 		if (c.cpuStats[i].Idle - n.Idle) >= jumpBackSeconds {
 			level.Debug(c.logger).Log("msg", jumpBackDebugMessage, "cpu", i, "old_value", c.cpuStats[i].Idle, "new_value", n.Idle)
 			c.cpuStats[i] = procfs.CPUStat{}
+			c.markInfoStale()
 		}
 
 		if n.Idle >= c.cpuStats[i].Idle {
@@ -451,7 +531,7 @@ func (c *cpuCollector) updateCPUStats(newStats []procfs.CPUStat) {
 			level.Debug(c.logger).Log("msg", "CPU Steal counter jumped backwards", "cpu", i, "old_value", c.cpuStats[i].Steal, "new_value", n.Steal)
 		}
 
-		if *enableCPUGuest {
+		if c.enableGuest {
 			if n.Guest >= c.cpuStats[i].Guest {
 				c.cpuStats[i].Guest = n.Guest
 			} else {
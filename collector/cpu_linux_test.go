@@ -17,10 +17,13 @@
 package collector
 
 import (
+	"io/ioutil"
+	"os"
 	"reflect"
 	"testing"
 
 	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
 )
 
@@ -28,8 +31,10 @@ func makeTestCPUCollector(s []procfs.CPUStat) *cpuCollector {
 	dup := make([]procfs.CPUStat, len(s))
 	copy(dup, s)
 	return &cpuCollector{
-		logger:   log.NewNopLogger(),
-		cpuStats: dup,
+		logger:      log.NewNopLogger(),
+		cpuStats:    dup,
+		enableStats: true,
+		enableGuest: true,
 	}
 }
 
@@ -104,3 +109,31 @@ func TestCPU(t *testing.T) {
 		t.Fatalf("should have %v CPU Stat: got %v", resetIdle, got)
 	}
 }
+
+func TestCPUInfoRereadFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cpuinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fs, err := procfs.NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := makeTestCPUCollector(nil)
+	c.fs = fs
+	c.cpuFlagsInfo = prometheus.NewDesc("node_cpu_flag_info", "", []string{"flag"}, nil)
+	c.cpuFlagsInfoValues = []string{"aes", "avx"}
+	c.markInfoStale()
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := c.updateInfo(ch); err != nil {
+		t.Fatalf("failed re-read must not fail the update: %v", err)
+	}
+	if got := len(ch); got != 2 {
+		t.Errorf("want 2 cached flag metrics, got %d", got)
+	}
+	if !c.cpuInfoStale {
+		t.Error("failed re-read must keep the info stale")
+	}
+}
@@ -20,12 +20,19 @@ import (
 	"strconv"
 
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/illumos/go-kstat"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// #include <unistd.h>
-import "C"
+// cpuSolarisModes maps the nanosecond counters of the cpu:<id>:sys kstat to
+// the mode label values used on Linux.
+var cpuSolarisModes = map[string]string{
+	"idle":   "cpu_nsec_idle",
+	"irq":    "cpu_nsec_intr",
+	"system": "cpu_nsec_kernel",
+	"user":   "cpu_nsec_user",
+}
 
 type cpuCollector struct {
 	cpu    typedDesc
@@ -43,9 +50,9 @@ func NewCpuCollector(logger log.Logger) (Collector, error) {
 	}, nil
 }
 
+// Update implements Collector. All cpu:<id>:sys kstats get walked, so CPU ids
+// need not be contiguous. Offline CPUs have no such kstat.
 func (c *cpuCollector) Update(ch chan<- prometheus.Metric) error {
-	ncpus := C.sysconf(C._SC_NPROCESSORS_ONLN)
-
 	tok, err := kstat.Open()
 	if err != nil {
 		return err
@@ -53,25 +60,26 @@ func (c *cpuCollector) Update(ch chan<- prometheus.Metric) error {
 
 	defer tok.Close()
 
-	for cpu := 0; cpu < int(ncpus); cpu++ {
-		ksCPU, err := tok.Lookup("cpu", cpu, "sys")
-		if err != nil {
-			return err
+	found := false
+	for _, ksCPU := range tok.All() {
+		if ksCPU.Module != "cpu" || ksCPU.Name != "sys" {
+			continue
 		}
-
-		for k, v := range map[string]string{
-			"idle":   "cpu_ticks_idle",
-			"kernel": "cpu_ticks_kernel",
-			"user":   "cpu_ticks_user",
-			"wait":   "cpu_ticks_wait",
-		} {
-			kstatValue, err := ksCPU.GetNamed(v)
+		cpu := strconv.Itoa(ksCPU.Instance)
+		for mode, stat := range cpuSolarisModes {
+			kstatValue, err := ksCPU.GetNamed(stat)
 			if err != nil {
-				return err
+				level.Debug(c.logger).Log("msg", "kstat not available", "cpu", cpu, "stat", stat, "err", err)
+				continue
 			}
 
-			ch <- c.cpu.mustNewConstMetric(float64(kstatValue.UintVal), strconv.Itoa(cpu), k)
+			ch <- c.cpu.mustNewConstMetric(float64(kstatValue.UintVal)/1e9, cpu, mode)
+			found = true
 		}
 	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No cpu:*:sys kstats found")
+		return ErrNoData
+	}
 	return nil
 }
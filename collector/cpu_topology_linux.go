@@ -0,0 +1,113 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocpu_topology
+// +build !nocpu_topology
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cpuTopologyCollector struct {
+	info   typedDesc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("cpu_topology", defaultDisabled, NewCPUTopologyCollector)
+}
+
+// NewCPUTopologyCollector returns a new Collector exposing the position of
+// each CPU in the package/die/cluster/core hierarchy.
+func NewCPUTopologyCollector(logger log.Logger) (Collector, error) {
+	return &cpuTopologyCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "topology_info"),
+			"Topology of the CPU. Levels not supported by the architecture or kernel are reported as 0.",
+			[]string{"cpu", "package", "die", "cluster", "core", "thread"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. die_id is available since Linux 5.2,
+// cluster_id since Linux 5.16.
+func (c *cpuTopologyCollector) Update(ch chan<- prometheus.Metric) error {
+	cpus, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*"))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, cpu := range cpus {
+		dir := filepath.Join(cpu, "topology")
+		// offline CPUs have no topology
+		if _, err := os.Stat(dir); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		id := strings.TrimPrefix(filepath.Base(cpu), "cpu")
+		ch <- c.info.mustNewConstMetric(1, id,
+			readCPUTopologyID(dir, "physical_package_id"),
+			readCPUTopologyID(dir, "die_id"),
+			readCPUTopologyID(dir, "cluster_id"),
+			readCPUTopologyID(dir, "core_id"),
+			c.threadIndex(dir, id),
+		)
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No CPU topology found")
+		return ErrNoData
+	}
+	return nil
+}
+
+// threadIndex returns the index of the given CPU within its core's sibling
+// list, i.e. the hyperthread/strand number.
+func (c *cpuTopologyCollector) threadIndex(dir, cpu string) string {
+	list, err := readStringFromFile(filepath.Join(dir, "thread_siblings_list"))
+	if err != nil {
+		return "0"
+	}
+	siblings, err := parseCPUList(list)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Invalid thread_siblings_list", "cpu", cpu, "err", err)
+		return "0"
+	}
+	ids := make([]int, 0, len(siblings))
+	for id := range siblings {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	n, _ := strconv.Atoi(cpu)
+	for i, id := range ids {
+		if id == n {
+			return strconv.Itoa(i)
+		}
+	}
+	return "0"
+}
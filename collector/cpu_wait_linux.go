@@ -0,0 +1,109 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocpu_wait
+// +build !nocpu_wait
+
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cpuWaitCollector struct {
+	pressure, runnable, existing typedDesc
+	logger                       log.Logger
+}
+
+func init() {
+	registerCollector("cpu_wait", defaultEnabled, NewCPUWaitCollector)
+}
+
+// NewCPUWaitCollector returns a new Collector exposing the run queue length
+// relative to the number of online CPUs.
+func NewCPUWaitCollector(logger log.Logger) (Collector, error) {
+	return &cpuWaitCollector{
+		pressure: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "runqueue_pressure"),
+			"Number of runnable scheduling entities per online CPU (0 = idle, 1 = fully loaded, > 1 = overloaded).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		runnable: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "runnable_processes"),
+			"Number of currently runnable scheduling entities (processes, threads).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		existing: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "existing_processes"),
+			"Number of currently existing scheduling entities (processes, threads).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The 4th field of /proc/loadavg counts runnable
+// tasks only, tasks in uninterruptible sleep are not included (in contrast to
+// the load average).
+func (c *cpuWaitCollector) Update(ch chan<- prometheus.Metric) error {
+	data, err := readStringFromFile(procFilePath("loadavg"))
+	if err != nil {
+		return err
+	}
+	runnable, existing, err := parseLoadavgTasks(data)
+	if err != nil {
+		return err
+	}
+	online, err := readStringFromFile(sysFilePath("devices/system/cpu/online"))
+	if err != nil {
+		return err
+	}
+	cpus, err := parseCPUList(online)
+	if err != nil {
+		return err
+	}
+
+	ch <- c.runnable.mustNewConstMetric(runnable)
+	ch <- c.existing.mustNewConstMetric(existing)
+	if len(cpus) > 0 {
+		ch <- c.pressure.mustNewConstMetric(runnable / float64(len(cpus)))
+	}
+	return nil
+}
+
+// parseLoadavgTasks returns the "<runnable>/<existing>" field of the given
+// /proc/loadavg content.
+func parseLoadavgTasks(data string) (float64, float64, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 4 {
+		return 0, 0, fmt.Errorf("unexpected loadavg content %q", data)
+	}
+	tasks := strings.SplitN(fields[3], "/", 2)
+	if len(tasks) != 2 {
+		return 0, 0, fmt.Errorf("unexpected loadavg tasks field %q", fields[3])
+	}
+	runnable, err := strconv.ParseFloat(tasks[0], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid loadavg tasks field %q: %w", fields[3], err)
+	}
+	existing, err := strconv.ParseFloat(tasks[1], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid loadavg tasks field %q: %w", fields[3], err)
+	}
+	return runnable, existing, nil
+}
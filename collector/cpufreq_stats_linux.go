@@ -0,0 +1,111 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocpufreq_stats
+// +build !nocpufreq_stats
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cpufreqStatsCollector struct {
+	time, transitions typedDesc
+	logger            log.Logger
+}
+
+func init() {
+	registerCollector("cpufreq-stats", defaultDisabled, NewCpufreqStatsCollector)
+}
+
+// NewCpufreqStatsCollector returns a new Collector exposing the time each
+// CPU spent at each frequency. Creates a series per CPU and frequency step.
+func NewCpufreqStatsCollector(logger log.Logger) (Collector, error) {
+	return &cpufreqStatsCollector{
+		time: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "frequency_seconds_total"),
+			"Time the CPU spent running at the frequency.",
+			[]string{"cpu", "frequency_hz"}, nil,
+		), prometheus.CounterValue},
+		transitions: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpu", "frequency_transitions_total"),
+			"Number of frequency transitions of the CPU.",
+			[]string{"cpu"}, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The stats directory is available only, if the
+// kernel has CONFIG_CPU_FREQ_STAT enabled and the driver does not manage
+// frequencies in hardware (e.g. intel_pstate in active mode).
+func (c *cpufreqStatsCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*/cpufreq/stats"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No cpufreq stats found")
+		return ErrNoData
+	}
+
+	for _, dir := range dirs {
+		cpu := strings.TrimPrefix(filepath.Base(filepath.Dir(filepath.Dir(dir))), "cpu")
+		if err := c.updateTimeInState(ch, cpu, filepath.Join(dir, "time_in_state")); err != nil {
+			return err
+		}
+		transitions, err := readUintFromFile(filepath.Join(dir, "total_trans"))
+		if err != nil {
+			return err
+		}
+		ch <- c.transitions.mustNewConstMetric(float64(transitions), cpu)
+	}
+	return nil
+}
+
+// updateTimeInState exposes the "<kHz> <10ms units>" lines of the given file.
+func (c *cpufreqStatsCollector) updateTimeInState(ch chan<- prometheus.Metric, cpu, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			return fmt.Errorf("invalid line in %s: %q", path, scanner.Text())
+		}
+		khz, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid line in %s: %q", path, scanner.Text())
+		}
+		ticks, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid line in %s: %q", path, scanner.Text())
+		}
+		ch <- c.time.mustNewConstMetric(float64(ticks)/100, cpu, strconv.FormatUint(khz*1000, 10))
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocpuidle
+// +build !nocpuidle
+
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cpuIdleCollector struct {
+	time, count, info typedDesc
+	logger            log.Logger
+}
+
+func init() {
+	registerCollector("cpuidle", defaultDisabled, NewCPUIdleCollector)
+}
+
+// NewCPUIdleCollector returns a new Collector exposing the residency of
+// each CPU in its idle (C-)states.
+func NewCPUIdleCollector(logger log.Logger) (Collector, error) {
+	return &cpuIdleCollector{
+		time: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpuidle", "state_time_seconds_total"),
+			"Time the CPU spent in the idle state.",
+			[]string{"cpu", "state"}, nil,
+		), prometheus.CounterValue},
+		count: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpuidle", "state_count_total"),
+			"Number of times the CPU entered the idle state.",
+			[]string{"cpu", "state"}, nil,
+		), prometheus.CounterValue},
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cpuidle", "state_info"),
+			"Name and description of the idle state.",
+			[]string{"cpu", "state", "name", "desc"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes
+// /sys/devices/system/cpu/cpu<N>/cpuidle/state<M>/. The state label is <M>.
+func (c *cpuIdleCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*/cpuidle/state[0-9]*"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No cpuidle states found, cpuidle driver not loaded?")
+		return ErrNoData
+	}
+	for _, dir := range dirs {
+		cpu := strings.TrimPrefix(filepath.Base(filepath.Dir(filepath.Dir(dir))), "cpu")
+		state := strings.TrimPrefix(filepath.Base(dir), "state")
+
+		usage, err := readUintFromFile(filepath.Join(dir, "usage"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read idle state", "cpu", cpu, "state", state, "err", err)
+			continue
+		}
+		ch <- c.count.mustNewConstMetric(float64(usage), cpu, state)
+		if usec, err := readUintFromFile(filepath.Join(dir, "time")); err == nil {
+			ch <- c.time.mustNewConstMetric(float64(usec)/1e6, cpu, state)
+		}
+		name, _ := readStringFromFile(filepath.Join(dir, "name"))
+		desc, _ := readStringFromFile(filepath.Join(dir, "desc"))
+		ch <- c.info.mustNewConstMetric(1, cpu, state, name, desc)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Jens Elkner (jel+prom@cs.uni-magdeburg.de)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && !nocpus
+// +build linux,!nocpus
+
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metric = "cpus"
+
+type cpusCollector struct {
+	desc     *prometheus.Desc
+	numaDesc *prometheus.Desc
+	total    int
+	logger   log.Logger
+}
+
+func init() {
+	registerCollector(metric, defaultEnabled, NewCpusCollector)
+}
+
+func NewCpusCollector(logger log.Logger) (Collector, error) {
+	return &cpusCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, metric, "total"),
+			"Total number of CPU cores or strands if HT or SMT is enabled.",
+			// You need to restart node-exporter if the CPU configuration gets
+			// changed.
+			[]string{"state"}, nil,
+		),
+		numaDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, metric, "numa_total"),
+			"Number of CPU cores or strands if HT or SMT is enabled per NUMA node.",
+			[]string{"node", "state"}, nil,
+		),
+		total:  0,
+		logger: logger,
+	}, nil
+}
+
+func (c *cpusCollector) Update(ch chan<- prometheus.Metric) error {
+	if c.total == 0 {
+		// Same as sysconf(_SC_NPROCESSORS_CONF) does - relative expensive,
+		// so run only once.
+		cpus, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*"))
+		if err != nil {
+			return err
+		}
+		c.total = len(cpus)
+	}
+	data, err := ioutil.ReadFile(sysFilePath("devices/system/cpu/online"))
+	if err != nil {
+		return err
+	}
+	online, err := parseCPUList(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", sysFilePath("devices/system/cpu/online"), err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.desc, prometheus.GaugeValue, float64(len(online)), "online",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.desc, prometheus.GaugeValue, float64(c.total-len(online)), "offline",
+	)
+	return c.updateNuma(ch, online)
+}
+
+// updateNuma counts the CPUs of each NUMA node using its cpumap. Because the
+// kernel drops offlined CPUs from the cpumap, the node's cpu* links (which
+// track present CPUs) get merged in as well.
+func (c *cpusCollector) updateNuma(ch chan<- prometheus.Metric, online map[int]bool) error {
+	nodes, err := filepath.Glob(sysFilePath("devices/system/node/node[0-9]*"))
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		data, err := ioutil.ReadFile(filepath.Join(node, "cpumap"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				level.Debug(c.logger).Log("msg", "NUMA node has no cpumap", "node", node)
+				continue
+			}
+			return err
+		}
+		cpus, err := parseCPUMap(string(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filepath.Join(node, "cpumap"), err)
+		}
+		links, err := filepath.Glob(filepath.Join(node, "cpu[0-9]*"))
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(link), "cpu")); err == nil {
+				cpus[id] = true
+			}
+		}
+
+		var on, off int
+		for id := range cpus {
+			if online[id] {
+				on++
+			} else {
+				off++
+			}
+		}
+		id := strings.TrimPrefix(filepath.Base(node), "node")
+		ch <- prometheus.MustNewConstMetric(c.numaDesc, prometheus.GaugeValue, float64(on), id, "online")
+		ch <- prometheus.MustNewConstMetric(c.numaDesc, prometheus.GaugeValue, float64(off), id, "offline")
+	}
+	return nil
+}
+
+// parseCPUMap returns the IDs of all bits set in the given cpumap, i.e. comma
+// separated 32bit hex words, most significant word first.
+func parseCPUMap(data string) (map[int]bool, error) {
+	cpus := make(map[int]bool)
+	words := strings.Split(strings.TrimSpace(data), ",")
+	for i := range words {
+		word, err := strconv.ParseUint(words[len(words)-1-i], 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		for bit := 0; word != 0; bit++ {
+			if word&1 == 1 {
+				cpus[i*32+bit] = true
+			}
+			word >>= 1
+		}
+	}
+	return cpus, nil
+}
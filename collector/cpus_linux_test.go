@@ -0,0 +1,42 @@
+// Copyright 2021 Jens Elkner (jel+prom@cs.uni-magdeburg.de)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && !nocpus
+// +build linux,!nocpus
+
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCPUMap(t *testing.T) {
+	for data, want := range map[string]map[int]bool{
+		"00000000\n":          {},
+		"0000000f\n":          {0: true, 1: true, 2: true, 3: true},
+		"00000001,00000100\n": {8: true, 32: true},
+	} {
+		got, err := parseCPUMap(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("cpumap %q: want %v, got %v", data, want, got)
+		}
+	}
+	if _, err := parseCPUMap("xyz"); err == nil {
+		t.Error("expected error for invalid cpumap")
+	}
+}
@@ -12,8 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build !nocpus
-// +build !nocpus
+//go:build !linux && !nocpus
+// +build !linux,!nocpus
 
 package collector
 
@@ -51,13 +51,9 @@ func NewCpusCollector(logger log.Logger) (Collector, error) {
 
 func (c *cpusCollector) Update(ch chan<- prometheus.Metric) error {
 	if c.total == 0 {
-		// On linux it scans the /sys/devices/system/cpu/ for dirs starting
-		// with 'cpu' - so relative expensive and run only once.
 		// On Solaris a "cheap" syscall.
 		c.total = C.sysconf(C._SC_NPROCESSORS_CONF)
 	}
-	// On linux this is a syscall now - counts the bits in the sched_affinity
-	// mask - see also /sys/devices/system/cpu/online
 	// On Solaris a "cheap" syscall.
 	num := C.sysconf(C._SC_NPROCESSORS_ONLN)
 
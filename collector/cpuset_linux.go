@@ -0,0 +1,139 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocpuset
+// +build !nocpuset
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const cpusetSubsystem = "cpuset"
+
+var (
+	cpusetCgroups = kingpin.Flag("collector.cpuset.cgroups", "Comma separated list of cgroup paths relative to the cgroup root, whose cpuset should be exposed in addition to the root cpuset.").Default("").String()
+)
+
+type cpusetCollector struct {
+	cpus, isolated, nohzFull typedDesc
+	cgroups                  []string
+	logger                   log.Logger
+}
+
+func init() {
+	registerCollector("cpuset", defaultDisabled, NewCpusetCollector)
+}
+
+// NewCpusetCollector returns a new Collector exposing cpuset cgroup stats.
+func NewCpusetCollector(logger log.Logger) (Collector, error) {
+	cgroups := []string{"/"}
+	for _, cgroup := range strings.Split(*cpusetCgroups, ",") {
+		cgroup = strings.Trim(strings.TrimSpace(cgroup), "/")
+		if cgroup != "" {
+			cgroups = append(cgroups, "/"+cgroup)
+		}
+	}
+	return &cpusetCollector{
+		cpus: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpusetSubsystem, "cpus_total"),
+			"Number of CPUs in the effective cpuset of the cgroup.",
+			[]string{"cgroup"}, nil,
+		), prometheus.GaugeValue},
+		isolated: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpusetSubsystem, "isolated_cpus"),
+			"Number of CPUs isolated from the scheduler via the isolcpus kernel parameter.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		nohzFull: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpusetSubsystem, "nohz_full_cpus"),
+			"Number of CPUs in adaptive-tick mode set via the nohz_full kernel parameter.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		cgroups: cgroups,
+		logger:  logger,
+	}, nil
+}
+
+// cpusetFile returns the path of the file containing the effective cpuset of
+// the given cgroup.
+func cpusetFile(cgroup string) string {
+	// cgroup v2 has a unified hierarchy
+	v2 := sysFilePath("fs/cgroup/cpuset.cpus.effective")
+	if _, err := os.Stat(v2); err == nil {
+		return sysFilePath(filepath.Join("fs/cgroup", cgroup, "cpuset.cpus.effective"))
+	}
+	return sysFilePath(filepath.Join("fs/cgroup/cpuset", cgroup, "cpuset.cpus"))
+}
+
+// Update implements Collector and exposes cpuset stats.
+func (c *cpusetCollector) Update(ch chan<- prometheus.Metric) error {
+	for _, cgroup := range c.cgroups {
+		data, err := readStringFromFile(cpusetFile(cgroup))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				if cgroup == "/" {
+					level.Debug(c.logger).Log("msg", "cpuset cgroup controller not found")
+					return ErrNoData
+				}
+				level.Debug(c.logger).Log("msg", "cgroup not found", "cgroup", cgroup)
+				continue
+			}
+			return err
+		}
+		cpus, err := parseCPUList(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse cpuset of cgroup %s: %w", cgroup, err)
+		}
+		ch <- c.cpus.mustNewConstMetric(float64(len(cpus)), cgroup)
+	}
+
+	data, err := readStringFromFile(sysFilePath("devices/system/cpu/isolated"))
+	if err != nil {
+		return err
+	}
+	isolated, err := parseCPUList(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse isolated CPUs: %w", err)
+	}
+	ch <- c.isolated.mustNewConstMetric(float64(len(isolated)))
+
+	// exists only if the kernel got built with CONFIG_NO_HZ_FULL
+	data, err = readStringFromFile(sysFilePath("devices/system/cpu/nohz_full"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if data == "(null)" {
+		// printed by some kernels, if no CPU is in adaptive-tick mode
+		data = ""
+	}
+	nohzFull, err := parseCPUList(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse nohz_full CPUs: %w", err)
+	}
+	ch <- c.nohzFull.mustNewConstMetric(float64(len(nohzFull)))
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocpuset
+// +build !nocpuset
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCpusetCollector(t *testing.T) {
+	*sysPath = "fixtures/cpuset"
+	*cpusetCgroups = "system.slice,missing.slice"
+	defer func() { *cpusetCgroups = "" }()
+	c, err := NewCpusetCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_cpuset_cpus_total Number of CPUs in the effective cpuset of the cgroup.
+# TYPE node_cpuset_cpus_total gauge
+node_cpuset_cpus_total{cgroup="/"} 8
+node_cpuset_cpus_total{cgroup="/system.slice"} 4
+# HELP node_cpuset_isolated_cpus Number of CPUs isolated from the scheduler via the isolcpus kernel parameter.
+# TYPE node_cpuset_isolated_cpus gauge
+node_cpuset_isolated_cpus 2
+# HELP node_cpuset_nohz_full_cpus Number of CPUs in adaptive-tick mode set via the nohz_full kernel parameter.
+# TYPE node_cpuset_nohz_full_cpus gauge
+node_cpuset_nohz_full_cpus 1
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
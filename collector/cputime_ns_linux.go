@@ -0,0 +1,156 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nocputime_ns
+// +build !nocputime_ns
+
+package collector
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cpuTimeNsUserHZ is the unit of the cgroup v1 cpuacct.stat values.
+const cpuTimeNsUserHZ = 100
+
+// cpuTimeNsFiles names the file and keys providing the CPU time of a
+// cgroup version as well as the factor to convert the values to seconds.
+type cpuTimeNsFiles struct {
+	stat, user, system string
+	factor             float64
+}
+
+var (
+	cpuTimeNsV1 = cpuTimeNsFiles{"cpuacct.stat", "user", "system", 1.0 / cpuTimeNsUserHZ}
+	cpuTimeNsV2 = cpuTimeNsFiles{"cpu.stat", "user_usec", "system_usec", 1e-6}
+)
+
+type cpuTimeNsCollector struct {
+	user, system typedDesc
+	logger       log.Logger
+}
+
+func init() {
+	registerCollector("cputime-ns", defaultDisabled, NewCPUTimeNsCollector)
+}
+
+// NewCPUTimeNsCollector returns a new Collector exposing the CPU time of
+// all cgroups having processes.
+func NewCPUTimeNsCollector(logger log.Logger) (Collector, error) {
+	return &cpuTimeNsCollector{
+		user: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cputime_ns", "user_seconds_total"),
+			"CPU time spent in user mode by the processes of the cgroup.",
+			[]string{"cgroup"}, nil,
+		), prometheus.CounterValue},
+		system: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cputime_ns", "system_seconds_total"),
+			"CPU time spent in kernel mode by the processes of the cgroup.",
+			[]string{"cgroup"}, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The cgroups of all processes get determined
+// via /proc/<pid>/cgroup. Summing up the CPU times of the processes would
+// make the values drop whenever a process exits, so the CPU time accounted
+// by the cgroup itself gets exposed instead (which includes exited processes
+// and descendant cgroups). If the cgroup v1 cpuacct controller is not
+// mounted, the cgroup v2 unified hierarchy gets used.
+func (c *cpuTimeNsCollector) Update(ch chan<- prometheus.Metric) error {
+	root, controller, files := "", "cpuacct", cpuTimeNsV1
+	for _, dir := range []string{"fs/cgroup/cpuacct", "fs/cgroup/cpu,cpuacct"} {
+		if _, err := os.Stat(sysFilePath(dir)); err == nil {
+			root = sysFilePath(dir)
+			break
+		}
+	}
+	if root == "" {
+		root, controller, files = sysFilePath("fs/cgroup"), "", cpuTimeNsV2
+		if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+			level.Debug(c.logger).Log("msg", "Neither cgroup v1 cpuacct controller nor cgroup v2 found")
+			return ErrNoData
+		}
+	}
+
+	procs, err := filepath.Glob(procFilePath("[0-9]*/cgroup"))
+	if err != nil {
+		return err
+	}
+	cgroups := make(map[string]bool)
+	for _, file := range procs {
+		if cgroup := readProcessCgroup(file, controller); cgroup != "" {
+			cgroups[cgroup] = true
+		}
+	}
+
+	for cgroup := range cgroups {
+		f, err := os.Open(filepath.Join(root, cgroup, files.stat))
+		if err != nil {
+			// cgroup is gone already
+			continue
+		}
+		stats, err := parseKeyValueStats(f)
+		f.Close()
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to parse "+files.stat, "cgroup", cgroup, "err", err)
+			continue
+		}
+		if v, ok := stats[files.user]; ok {
+			ch <- c.user.mustNewConstMetric(float64(v)*files.factor, cgroup)
+		}
+		if v, ok := stats[files.system]; ok {
+			ch <- c.system.mustNewConstMetric(float64(v)*files.factor, cgroup)
+		}
+	}
+	return nil
+}
+
+// readProcessCgroup returns the cgroup path of the given /proc/<pid>/cgroup
+// file for the given v1 controller or, if empty, the v2 hierarchy. Lines
+// look like "4:cpu,cpuacct:/system.slice/ssh.service" resp. "0::/init.scope".
+func readProcessCgroup(file, controller string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if controller == "" {
+			if parts[0] == "0" && parts[1] == "" {
+				return parts[2]
+			}
+			continue
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			if c == controller {
+				return parts[2]
+			}
+		}
+	}
+	return ""
+}
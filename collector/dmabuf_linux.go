@@ -0,0 +1,126 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nodmabuf
+// +build !nodmabuf
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type dmabufExporterStats struct {
+	bytes, count uint64
+}
+
+type dmabufCollector struct {
+	bytes, count, total typedDesc
+	logger              log.Logger
+}
+
+func init() {
+	registerCollector("dmabuf", defaultDisabled, NewDmabufCollector)
+}
+
+// NewDmabufCollector returns a new Collector exposing DMA buffer statistics.
+func NewDmabufCollector(logger log.Logger) (Collector, error) {
+	return &dmabufCollector{
+		bytes: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dmabuf", "total_bytes"),
+			"Size of all DMA buffers of the exporter in bytes.",
+			[]string{"exporter"}, nil,
+		), prometheus.GaugeValue},
+		count: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dmabuf", "count"),
+			"Number of DMA buffers of the exporter.",
+			[]string{"exporter"}, nil,
+		), prometheus.GaugeValue},
+		// A metric family must not mix label dimensions, so the system total
+		// needs its own name.
+		total: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dmabuf", "system_total_bytes"),
+			"Size of all DMA buffers in bytes.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the DMA buffers listed in
+// <debugfs>/dma_buf/bufinfo aggregated by exporter.
+func (c *dmabufCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(sysFilePath("kernel/debug/dma_buf/bufinfo"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "debugfs not mounted or kernel without DMA buffer support")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseDmabufInfo(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	var total uint64
+	for exporter, s := range stats {
+		ch <- c.bytes.mustNewConstMetric(float64(s.bytes), exporter)
+		ch <- c.count.mustNewConstMetric(float64(s.count), exporter)
+		total += s.bytes
+	}
+	ch <- c.total.mustNewConstMetric(float64(total))
+	return nil
+}
+
+// parseDmabufInfo aggregates the buffer lines of a bufinfo file, which look
+// like "<size> <flags> <mode> <count> <exp_name> [<ino> <name>]". Attachment,
+// fence and summary lines get skipped.
+func parseDmabufInfo(r io.Reader) (map[string]*dmabufExporterStats, error) {
+	stats := map[string]*dmabufExporterStats{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			// headers
+			continue
+		}
+		s, ok := stats[fields[4]]
+		if !ok {
+			s = &dmabufExporterStats{}
+			stats[fields[4]] = s
+		}
+		s.bytes += size
+		s.count++
+	}
+	return stats, scanner.Err()
+}
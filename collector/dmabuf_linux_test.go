@@ -0,0 +1,56 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nodmabuf
+// +build !nodmabuf
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDmabufInfo(t *testing.T) {
+	stats, err := parseDmabufInfo(strings.NewReader(`
+Dma-buf Objects:
+size    	flags   	mode    	count   	exp_name	ino     	name
+00032768	00000002	00080007	00000001	i915	00000139	<none>
+	Exclusive fence: i915 signalled
+	Attached Devices:
+	0000:00:02.0
+Total 1 devices attached
+
+00004096	00000002	00080007	00000003	i915	00000140	<none>
+	Attached Devices:
+Total 0 devices attached
+
+01048576	00000000	00080005	00000001	system	00000141	<none>
+	Attached Devices:
+Total 0 devices attached
+
+
+Total 3 objects, 1085440 bytes
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*dmabufExporterStats{
+		"i915":   {bytes: 36864, count: 2},
+		"system": {bytes: 1048576, count: 1},
+	}
+	if !reflect.DeepEqual(want, stats) {
+		t.Errorf("want %v, got %v", want, stats)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nodmesg
+// +build !nodmesg
+
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// dmesgLevels are the kernel log levels as defined in linux/kern_levels.h.
+var dmesgLevels = []string{"emerg", "alert", "crit", "err", "warn", "notice", "info", "debug"}
+
+var dmesgLevelsInclude = kingpin.Flag("collector.dmesg.levels", "Comma separated list of kernel log levels to count.").Default("emerg,alert,crit,err,warn").String()
+
+type dmesgCollector struct {
+	messages typedDesc
+	levels   map[int]bool
+	logger   log.Logger
+
+	mtx    sync.Mutex
+	last   float64
+	counts map[string]uint64
+}
+
+func init() {
+	registerCollector("dmesg", defaultDisabled, NewDmesgCollector)
+}
+
+// NewDmesgCollector returns a new Collector exposing the number of kernel
+// messages per log level.
+func NewDmesgCollector(logger log.Logger) (Collector, error) {
+	levels := map[int]bool{}
+	for _, l := range strings.Split(*dmesgLevelsInclude, ",") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		found := false
+		for i, name := range dmesgLevels {
+			if name == l {
+				levels[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid kernel log level %q", l)
+		}
+	}
+	counts := map[string]uint64{}
+	for i := range levels {
+		counts[dmesgLevels[i]] = 0
+	}
+	return &dmesgCollector{
+		messages: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dmesg", "messages_total"),
+			"Number of kernel messages seen in the ring buffer.",
+			[]string{"level"}, nil,
+		), prometheus.CounterValue},
+		levels: levels,
+		counts: counts,
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and counts the kernel messages logged since
+// the last scrape. Requires CAP_SYSLOG if kernel.dmesg_restrict is set.
+func (c *dmesgCollector) Update(ch chan<- prometheus.Metric) error {
+	size, err := unix.Klogctl(unix.SYSLOG_ACTION_SIZE_BUFFER, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't get kernel ring buffer size: %w", err)
+	}
+	buf := make([]byte, size)
+	n, err := unix.Klogctl(unix.SYSLOG_ACTION_READ_ALL, buf)
+	if err != nil {
+		return fmt.Errorf("couldn't read kernel ring buffer: %w", err)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.last = countDmesgMessages(buf[:n], c.last, c.levels, c.counts)
+	for level, count := range c.counts {
+		ch <- c.messages.mustNewConstMetric(float64(count), level)
+	}
+	return nil
+}
+
+// countDmesgMessages adds the number of messages of the given levels with a
+// timestamp after since to counts and returns the timestamp of the last
+// message. Lines look like "<3>[   12.345678] message". Messages without a
+// timestamp (printk.time=0) cannot be told apart from already seen ones and
+// get ignored.
+func countDmesgMessages(buf []byte, since float64, levels map[int]bool, counts map[string]uint64) float64 {
+	last := since
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		if len(line) < 3 || line[0] != '<' {
+			continue
+		}
+		end := bytes.IndexByte(line, '>')
+		if end < 0 {
+			continue
+		}
+		prio, err := strconv.Atoi(string(line[1:end]))
+		if err != nil {
+			continue
+		}
+		rest := line[end+1:]
+		if len(rest) == 0 || rest[0] != '[' {
+			continue
+		}
+		tsEnd := bytes.IndexByte(rest, ']')
+		if tsEnd < 0 {
+			continue
+		}
+		ts, err := strconv.ParseFloat(string(bytes.TrimSpace(rest[1:tsEnd])), 64)
+		if err != nil || ts <= since {
+			continue
+		}
+		if ts > last {
+			last = ts
+		}
+		// the priority includes the facility
+		if level := prio & 7; levels[level] {
+			counts[dmesgLevels[level]]++
+		}
+	}
+	return last
+}
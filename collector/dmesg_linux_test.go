@@ -0,0 +1,52 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nodmesg
+// +build !nodmesg
+
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountDmesgMessages(t *testing.T) {
+	buf := []byte(`<6>[    0.000000] Linux version 5.4.0
+<3>[    1.234567] ata1: SRST failed (errno=-16)
+<4>[    2.000000] ACPI Warning: SystemIO range conflicts
+<11>[    3.500000] user space error
+no prefix
+<3>missing timestamp
+`)
+	levels := map[int]bool{3: true, 4: true}
+	counts := map[string]uint64{"err": 0, "warn": 0}
+
+	last := countDmesgMessages(buf, 0, levels, counts)
+	if last != 3.5 {
+		t.Errorf("want last timestamp 3.5, got %f", last)
+	}
+	if want := map[string]uint64{"err": 2, "warn": 1}; !reflect.DeepEqual(want, counts) {
+		t.Errorf("want %v, got %v", want, counts)
+	}
+
+	// already seen messages must not be counted again
+	buf = append(buf, []byte("<4>[    4.000000] new warning\n")...)
+	last = countDmesgMessages(buf, last, levels, counts)
+	if last != 4 {
+		t.Errorf("want last timestamp 4, got %f", last)
+	}
+	if want := map[string]uint64{"err": 2, "warn": 2}; !reflect.DeepEqual(want, counts) {
+		t.Errorf("want %v, got %v", want, counts)
+	}
+}
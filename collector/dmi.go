@@ -19,20 +19,29 @@
 package collector
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs/sysfs"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
+var dmiOEM = kingpin.Flag("collector.dmi.oem", "Expose the OEM strings (SMBIOS type 11) as well.").Default("false").Bool()
+
 type dmiCollector struct {
-	infoDesc *prometheus.Desc
-	values   []string
+	infoDesc   *prometheus.Desc
+	values     []string
+	oemDesc    *prometheus.Desc
+	oemStrings []string
 }
 
 func init() {
@@ -85,6 +94,13 @@ func NewDMICollector(logger log.Logger) (Collector, error) {
 		}
 	}
 
+	var oemStrings []string
+	if *dmiOEM {
+		if oemStrings, err = readDMIOEMStrings(logger); err != nil {
+			return nil, err
+		}
+	}
+
 	// Construct DMI metric only once since it will not change until the next reboot.
 	return &dmiCollector{
 		infoDesc: prometheus.NewDesc(
@@ -93,13 +109,73 @@ func NewDMICollector(logger log.Logger) (Collector, error) {
 			labels, nil,
 		),
 		values: values,
+		oemDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dmi", "oem_string_info"),
+			"The OEM strings of the DMI (SMBIOS type 11). Always 1.",
+			[]string{"index", "value"}, nil,
+		),
+		oemStrings: oemStrings,
 	}, nil
 }
 
 func (c *dmiCollector) Update(ch chan<- prometheus.Metric) error {
-	if len(c.values) == 0 {
+	if len(c.values) == 0 && len(c.oemStrings) == 0 {
 		return ErrNoData
 	}
-	ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1.0, c.values...)
+	if len(c.values) != 0 {
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1.0, c.values...)
+	}
+	for i, s := range c.oemStrings {
+		ch <- prometheus.MustNewConstMetric(c.oemDesc, prometheus.GaugeValue, 1.0, strconv.Itoa(i+1), s)
+	}
 	return nil
 }
+
+// readDMIOEMStrings returns the strings of all OEM Strings structures found
+// in /sys/firmware/dmi/entries/. The raw files are readable by root only,
+// so unreadable ones get skipped.
+func readDMIOEMStrings(logger log.Logger) ([]string, error) {
+	entries, err := filepath.Glob(sysFilePath("firmware/dmi/entries/11-*/raw"))
+	if err != nil {
+		return nil, err
+	}
+	var oemStrings []string
+	for _, entry := range entries {
+		raw, err := ioutil.ReadFile(entry)
+		if err != nil {
+			level.Debug(logger).Log("msg", "Skipping unreadable DMI OEM Strings structure", "entry", entry, "err", err)
+			continue
+		}
+		s, err := parseDMIOEMStrings(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry, err)
+		}
+		oemStrings = append(oemStrings, s...)
+	}
+	return oemStrings, nil
+}
+
+// parseDMIOEMStrings parses a raw SMBIOS type 11 structure: a 4 byte header
+// (type, length, handle), the string count and the string-set, i.e.
+// NUL terminated strings followed by an additional NUL.
+func parseDMIOEMStrings(raw []byte) ([]string, error) {
+	if len(raw) < 5 || raw[0] != 11 {
+		return nil, fmt.Errorf("not an OEM Strings structure")
+	}
+	length := int(raw[1])
+	if length < 5 || length > len(raw) {
+		return nil, fmt.Errorf("invalid structure length %d", length)
+	}
+	count := int(raw[4])
+	strs := make([]string, 0, count)
+	rest := raw[length:]
+	for len(strs) < count {
+		end := bytes.IndexByte(rest, 0)
+		if end <= 0 {
+			return nil, fmt.Errorf("string-set contains %d of %d strings, only", len(strs), count)
+		}
+		strs = append(strs, strings.ToValidUTF8(string(rest[:end]), "�"))
+		rest = rest[end+1:]
+	}
+	return strs, nil
+}
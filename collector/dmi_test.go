@@ -0,0 +1,47 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux && !nodmi
+// +build linux,!nodmi
+
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDMIOEMStrings(t *testing.T) {
+	raw := []byte{11, 5, 0x2a, 0x00, 2}
+	raw = append(raw, "i-0123456789abcdef\x00eu-central-1\x00\x00"...)
+
+	got, err := parseDMIOEMStrings(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"i-0123456789abcdef", "eu-central-1"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	for _, bad := range [][]byte{
+		{11, 5, 0, 0},                   // too short
+		{1, 5, 0, 0, 0, 0, 0},           // wrong type
+		{11, 9, 0, 0, 1, 0, 0},          // length beyond data
+		{11, 5, 0, 0, 2, 'a', 0, 0},     // missing string
+		{11, 5, 0, 0, 1, 'a', 'b', 'c'}, // unterminated string
+	} {
+		if _, err := parseDMIOEMStrings(bad); err == nil {
+			t.Errorf("want error for %v", bad)
+		}
+	}
+}
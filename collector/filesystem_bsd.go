@@ -76,6 +76,7 @@ func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
 			size:      float64(mnt[i].f_blocks) * float64(mnt[i].f_bsize),
 			free:      float64(mnt[i].f_bfree) * float64(mnt[i].f_bsize),
 			avail:     float64(mnt[i].f_bavail) * float64(mnt[i].f_bsize),
+			reserved:  (float64(mnt[i].f_bfree) - float64(mnt[i].f_bavail)) * float64(mnt[i].f_bsize),
 			files:     float64(mnt[i].f_files),
 			filesFree: float64(mnt[i].f_ffree),
 			ro:        ro,
@@ -67,6 +67,7 @@ type filesystemCollector struct {
 	excludedMountPointsPattern    *regexp.Regexp
 	excludedFSTypesPattern        *regexp.Regexp
 	sizeDesc, freeDesc, availDesc *prometheus.Desc
+	reservedDesc                  *prometheus.Desc
 	filesDesc, filesFreeDesc      *prometheus.Desc
 	roDesc, deviceErrorDesc       *prometheus.Desc
 	logger                        log.Logger
@@ -79,6 +80,7 @@ type filesystemLabels struct {
 type filesystemStats struct {
 	labels            filesystemLabels
 	size, free, avail float64
+	reserved          float64
 	files, filesFree  float64
 	ro, deviceError   float64
 }
@@ -131,6 +133,12 @@ func NewFilesystemCollector(logger log.Logger) (Collector, error) {
 		filesystemLabelNames, nil,
 	)
 
+	reservedDesc := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "reserved_bytes"),
+		"Filesystem space reserved for root in bytes.",
+		filesystemLabelNames, nil,
+	)
+
 	filesDesc := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, "files"),
 		"Filesystem total file nodes.",
@@ -161,6 +169,7 @@ func NewFilesystemCollector(logger log.Logger) (Collector, error) {
 		sizeDesc:                   sizeDesc,
 		freeDesc:                   freeDesc,
 		availDesc:                  availDesc,
+		reservedDesc:               reservedDesc,
 		filesDesc:                  filesDesc,
 		filesFreeDesc:              filesFreeDesc,
 		roDesc:                     roDesc,
@@ -202,6 +211,10 @@ func (c *filesystemCollector) Update(ch chan<- prometheus.Metric) error {
 			c.availDesc, prometheus.GaugeValue,
 			s.avail, s.labels.device, s.labels.mountPoint, s.labels.fsType,
 		)
+		ch <- prometheus.MustNewConstMetric(
+			c.reservedDesc, prometheus.GaugeValue,
+			s.reserved, s.labels.device, s.labels.mountPoint, s.labels.fsType,
+		)
 		ch <- prometheus.MustNewConstMetric(
 			c.filesDesc, prometheus.GaugeValue,
 			s.files, s.labels.device, s.labels.mountPoint, s.labels.fsType,
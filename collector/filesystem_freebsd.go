@@ -71,6 +71,7 @@ func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
 			size:      float64(fs.Blocks) * float64(fs.Bsize),
 			free:      float64(fs.Bfree) * float64(fs.Bsize),
 			avail:     float64(fs.Bavail) * float64(fs.Bsize),
+			reserved:  (float64(fs.Bfree) - float64(fs.Bavail)) * float64(fs.Bsize),
 			files:     float64(fs.Files),
 			filesFree: float64(fs.Ffree),
 			ro:        ro,
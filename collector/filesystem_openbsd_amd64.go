@@ -68,6 +68,7 @@ func (c *filesystemCollector) GetStats() (stats []filesystemStats, err error) {
 			size:      float64(v.F_blocks) * float64(v.F_bsize),
 			free:      float64(v.F_bfree) * float64(v.F_bsize),
 			avail:     float64(v.F_bavail) * float64(v.F_bsize),
+			reserved:  (float64(v.F_bfree) - float64(v.F_bavail)) * float64(v.F_bsize),
 			files:     float64(v.F_files),
 			filesFree: float64(v.F_ffree),
 			ro:        ro,
@@ -0,0 +1,193 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nofscache
+// +build !nofscache
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const fscacheSubsystem = "fscache"
+
+var (
+	fscacheInvalidChars = regexp.MustCompile("[^a-z0-9_]+")
+
+	// fscacheHistogramTypes are the value columns of /proc/fs/fscache/histogram.
+	fscacheHistogramTypes = []string{"obj_inst", "op_runs", "obj_runs", "retrv_dly", "retrievls"}
+	// fscacheHistogramBuckets are the upper bounds in seconds, the per
+	// jiffy rows get folded into.
+	fscacheHistogramBuckets = []float64{0.001, 0.01, 0.1, 1}
+)
+
+type fscacheCollector struct {
+	histogram *prometheus.Desc
+	logger    log.Logger
+}
+
+// fscacheHistogram is a histogram with non-cumulative bucket counts.
+type fscacheHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func init() {
+	registerCollector("fscache", defaultEnabled, NewFscacheCollector)
+}
+
+// NewFscacheCollector returns a new Collector exposing FS-Cache stats.
+func NewFscacheCollector(logger log.Logger) (Collector, error) {
+	return &fscacheCollector{
+		histogram: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, fscacheSubsystem, "histogram_seconds"),
+			"Histogram of the FS-Cache operation times from /proc/fs/fscache/histogram.",
+			[]string{"type"}, nil,
+		),
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes /proc/fs/fscache/stats. Like
+// vmstat the fields get exposed untyped, because their set and meaning
+// depends on the kernel version.
+func (c *fscacheCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("fs/fscache/stats"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "FS-Cache not available")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseFscacheStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for name, value := range stats {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, fscacheSubsystem, name),
+				fmt.Sprintf("/proc/fs/fscache/stats information field %s.", name),
+				nil, nil),
+			prometheus.UntypedValue,
+			value,
+		)
+	}
+
+	// available if the kernel has CONFIG_FSCACHE_HISTOGRAM set, only
+	hfile, err := os.Open(procFilePath("fs/fscache/histogram"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer hfile.Close()
+
+	histograms, err := parseFscacheHistogram(hfile)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", hfile.Name(), err)
+	}
+	for i, h := range histograms {
+		buckets := make(map[float64]uint64, len(fscacheHistogramBuckets))
+		var cumulative uint64
+		for j, bound := range fscacheHistogramBuckets {
+			cumulative += h.counts[j]
+			buckets[bound] = cumulative
+		}
+		ch <- prometheus.MustNewConstHistogram(c.histogram, h.count, h.sum, buckets, fscacheHistogramTypes[i])
+	}
+	return nil
+}
+
+// parseFscacheStats parses lines like "Cookies: idx=2 dat=0 spc=0" into
+// metric names like "cookies_idx".
+func parseFscacheStats(r io.Reader) (map[string]float64, error) {
+	stats := map[string]float64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			// e.g. the "FS-Cache statistics" headline
+			continue
+		}
+		section := strings.ToLower(strings.TrimSpace(parts[0]))
+		for _, kv := range strings.Fields(parts[1]) {
+			f := strings.SplitN(kv, "=", 2)
+			if len(f) != 2 {
+				continue
+			}
+			value, err := strconv.ParseFloat(f[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in %q: %w", kv, err)
+			}
+			name := fscacheInvalidChars.ReplaceAllString(section+"_"+strings.ToLower(f[0]), "_")
+			stats[name] = value
+		}
+	}
+	return stats, scanner.Err()
+}
+
+// parseFscacheHistogram parses the per jiffy rows "<jiffies> <secs>s <values>"
+// of /proc/fs/fscache/histogram and folds them into fscacheHistogramBuckets.
+func parseFscacheHistogram(r io.Reader) ([]fscacheHistogram, error) {
+	histograms := make([]fscacheHistogram, len(fscacheHistogramTypes))
+	for i := range histograms {
+		histograms[i].counts = make([]uint64, len(fscacheHistogramBuckets)+1)
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2+len(fscacheHistogramTypes) || !strings.HasSuffix(fields[1], "s") {
+			// headlines
+			continue
+		}
+		secs, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "s"), 64)
+		if err != nil {
+			continue
+		}
+		bucket := len(fscacheHistogramBuckets)
+		for j, bound := range fscacheHistogramBuckets {
+			if secs <= bound {
+				bucket = j
+				break
+			}
+		}
+		for i := range histograms {
+			n, err := strconv.ParseUint(fields[2+i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q: %w", fields[2+i], err)
+			}
+			histograms[i].counts[bucket] += n
+			histograms[i].count += n
+			histograms[i].sum += float64(n) * secs
+		}
+	}
+	return histograms, scanner.Err()
+}
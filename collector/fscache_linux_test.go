@@ -0,0 +1,69 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nofscache
+// +build !nofscache
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFscacheStats(t *testing.T) {
+	stats, err := parseFscacheStats(strings.NewReader(`FS-Cache statistics
+Cookies: idx=2 dat=15 spc=0
+Pages  : mrk=7 unc=3
+Retrvls: n=4 ok=1 wt=0 nod=3 nbf=0 int=0 oom=0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"cookies_idx": 2, "cookies_dat": 15, "cookies_spc": 0,
+		"pages_mrk": 7, "pages_unc": 3,
+		"retrvls_n": 4, "retrvls_ok": 1, "retrvls_wt": 0, "retrvls_nod": 3,
+		"retrvls_nbf": 0, "retrvls_int": 0, "retrvls_oom": 0,
+	}
+	if !reflect.DeepEqual(want, stats) {
+		t.Errorf("want %v, got %v", want, stats)
+	}
+}
+
+func TestParseFscacheHistogram(t *testing.T) {
+	histograms, err := parseFscacheHistogram(strings.NewReader(`JIFS  SECS  OBJ INST  OP RUNS   OBJ RUNS  RETRV DLY RETRIEVLS
+===== ===== ========= ========= ========= ========= =========
+    0 0.000s        5         1         0         0         0
+    1 0.004s        2         0         0         0         0
+   50 0.200s        1         0         0         0         0
+  500 2.000s        1         0         0         0         0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objInst := histograms[0]
+	if want := []uint64{5, 2, 0, 1, 1}; !reflect.DeepEqual(want, objInst.counts) {
+		t.Errorf("want bucket counts %v, got %v", want, objInst.counts)
+	}
+	if objInst.count != 9 {
+		t.Errorf("want count 9, got %d", objInst.count)
+	}
+	if want := 2*0.004 + 0.2 + 2; objInst.sum != want {
+		t.Errorf("want sum %f, got %f", want, objInst.sum)
+	}
+	if histograms[1].count != 1 {
+		t.Errorf("want op_runs count 1, got %d", histograms[1].count)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noftrace
+// +build !noftrace
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ftraceCollector struct {
+	bufferSize, entries, overrun, commitOverrun, dropped typedDesc
+	logger                                               log.Logger
+}
+
+func init() {
+	registerCollector("ftrace", defaultDisabled, NewFtraceCollector)
+}
+
+// NewFtraceCollector returns a new Collector exposing the per CPU ftrace ring
+// buffer stats.
+func NewFtraceCollector(logger log.Logger) (Collector, error) {
+	desc := func(name, help string, valueType prometheus.ValueType) typedDesc {
+		return typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ftrace", name),
+			help,
+			[]string{"cpu"}, nil,
+		), valueType}
+	}
+	return &ftraceCollector{
+		bufferSize:    desc("buffer_size_bytes", "Size of the ftrace ring buffer of the CPU.", prometheus.GaugeValue),
+		entries:       desc("entries", "Number of events currently in the ftrace ring buffer of the CPU.", prometheus.GaugeValue),
+		overrun:       desc("overrun_total", "Number of events lost because the ftrace ring buffer of the CPU was full.", prometheus.CounterValue),
+		commitOverrun: desc("commit_overrun_total", "Number of events lost because of nested events wrapping the ftrace ring buffer of the CPU.", prometheus.CounterValue),
+		dropped:       desc("dropped_events_total", "Number of events dropped because the ftrace ring buffer of the CPU was full and overwriting is disabled.", prometheus.CounterValue),
+		logger:        logger,
+	}, nil
+}
+
+// Update implements Collector. tracefs gets looked up at its own mount point
+// (Linux >= 4.1) and below debugfs.
+func (c *ftraceCollector) Update(ch chan<- prometheus.Metric) error {
+	var root string
+	for _, dir := range []string{"kernel/tracing", "kernel/debug/tracing"} {
+		if _, err := os.Stat(sysFilePath(filepath.Join(dir, "per_cpu"))); err == nil {
+			root = sysFilePath(dir)
+			break
+		}
+	}
+	if root == "" {
+		level.Debug(c.logger).Log("msg", "tracefs not mounted")
+		return ErrNoData
+	}
+
+	cpus, err := filepath.Glob(filepath.Join(root, "per_cpu/cpu[0-9]*"))
+	if err != nil {
+		return err
+	}
+	for _, dir := range cpus {
+		cpu := strings.TrimPrefix(filepath.Base(dir), "cpu")
+		// e.g. "1408" or "7 (expanded: 1408)" if the buffer was never used
+		if size, err := readStringFromFile(filepath.Join(dir, "buffer_size_kb")); err == nil {
+			fields := strings.Fields(size)
+			if len(fields) > 0 {
+				if kb, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+					ch <- c.bufferSize.mustNewConstMetric(float64(kb*1024), cpu)
+				}
+			}
+		}
+
+		stats, err := readFtraceStats(filepath.Join(dir, "stats"))
+		if err != nil {
+			return err
+		}
+		for key, desc := range map[string]typedDesc{
+			"entries":        c.entries,
+			"overrun":        c.overrun,
+			"commit overrun": c.commitOverrun,
+			"dropped events": c.dropped,
+		} {
+			if v, ok := stats[key]; ok {
+				ch <- desc.mustNewConstMetric(v, cpu)
+			}
+		}
+	}
+	return nil
+}
+
+// readFtraceStats parses the "<key>: <value>" lines of a per_cpu stats file.
+func readFtraceStats(path string) (map[string]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stats := map[string]float64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line in %s: %q", path, scanner.Text())
+		}
+		stats[strings.TrimSpace(kv[0])] = value
+	}
+	return stats, scanner.Err()
+}
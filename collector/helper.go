@@ -14,11 +14,17 @@
 package collector
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 func readUintFromFile(path string) (uint64, error) {
@@ -33,6 +39,132 @@ func readUintFromFile(path string) (uint64, error) {
 	return value, nil
 }
 
+// readStringFromFile returns the content of the given file with leading and
+// trailing white space removed.
+func readStringFromFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readCPUTopologyID returns the content of the given file in the given CPU
+// topology directory, or "0" if it is not available.
+func readCPUTopologyID(dir, name string) string {
+	id, err := readStringFromFile(filepath.Join(dir, name))
+	if err != nil {
+		return "0"
+	}
+	return id
+}
+
+// readProcSelf returns the PID of node_exporter as seen by the procfs given
+// via --path.procfs, which may belong to another PID namespace, or "self"
+// if it is not resolvable.
+func readProcSelf() string {
+	pid, err := os.Readlink(procFilePath("self"))
+	if err != nil {
+		return "self"
+	}
+	return pid
+}
+
+// readSCSICounterFromFile reads a SCSI device I/O counter, which the kernel
+// exposes as hex number, e.g. 0x1a3.
+func readSCSICounterFromFile(path string) (uint64, error) {
+	data, err := readStringFromFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(data, 0, 64)
+}
+
+// parseKeyValueStats parses files like /proc/net/tls_stat, which contain a
+// single "<key> <value>" pair per line.
+func parseKeyValueStats(r io.Reader) (map[string]uint64, error) {
+	var (
+		stats   = map[string]uint64{}
+		scanner = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line: %q", scanner.Text())
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", fields[0], err)
+		}
+		stats[fields[0]] = value
+	}
+	return stats, scanner.Err()
+}
+
+// parseCPUList returns the IDs of all CPUs in the given cpulist, e.g. "0-3,8".
+func parseCPUList(data string) (map[int]bool, error) {
+	cpus := make(map[int]bool)
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return cpus, nil
+	}
+	for _, r := range strings.Split(data, ",") {
+		bounds := strings.SplitN(r, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		for id := first; id <= last; id++ {
+			cpus[id] = true
+		}
+	}
+	return cpus, nil
+}
+
+// parseSysfsSelection returns the bracketed, i.e. selected value of the given
+// sysfs setting, e.g. "madvise" for "always [madvise] never".
+func parseSysfsSelection(data string) string {
+	for _, v := range strings.Fields(data) {
+		if strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
+			return v[1 : len(v)-1]
+		}
+	}
+	return ""
+}
+
+// camelCaseToSnake converts names like InStateSeqError into
+// in_state_seq_error. Acronyms are kept together, e.g. InSCTPPacks becomes
+// in_sctp_packs.
+func camelCaseToSnake(name string) string {
+	var (
+		b     strings.Builder
+		runes = []rune(name)
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// a word starts after a lower case letter or digit, or with the
+			// last upper case letter of an acronym followed by lower case
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // Take a []byte{} and return a string based on null termination.
 // This is useful for situations where the OS has returned a null terminated
 // string to use.
@@ -62,3 +194,23 @@ var metricNameRegex = regexp.MustCompile(`_*[^0-9A-Za-z_]+_*`)
 func SanitizeMetricName(metricName string) string {
 	return metricNameRegex.ReplaceAllString(metricName, "_")
 }
+
+// unescapeOctal replaces the \ooo escapes the kernel uses for whitespace and
+// backslashes in paths and names (seq_escape) with the real characters.
+func unescapeOctal(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
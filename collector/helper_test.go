@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -80,3 +81,37 @@ func TestSanitizeMetricName(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCPUList(t *testing.T) {
+	for data, want := range map[string]map[int]bool{
+		"\n":        {},
+		"0\n":       {0: true},
+		"0-2,5\n":   {0: true, 1: true, 2: true, 5: true},
+		"1,3-4,7\n": {1: true, 3: true, 4: true, 7: true},
+	} {
+		got, err := parseCPUList(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("cpulist %q: want %v, got %v", data, want, got)
+		}
+	}
+}
+
+func TestCamelCaseToSnake(t *testing.T) {
+	for name, want := range map[string]string{
+		"CurrEstab":               "curr_estab",
+		"InSCTPPacks":             "in_sctp_packs",
+		"OutSCTPPacks":            "out_sctp_packs",
+		"T1InitExpireds":          "t1_init_expireds",
+		"T5ShutdownGuardExpireds": "t5_shutdown_guard_expireds",
+		"InStateSeqError":         "in_state_seq_error",
+		"OutPolBlock":             "out_pol_block",
+		"RTO":                     "rto",
+	} {
+		if got := camelCaseToSnake(name); got != want {
+			t.Errorf("%s: want %q, got %q", name, want, got)
+		}
+	}
+}
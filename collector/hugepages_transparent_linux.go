@@ -0,0 +1,104 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nohugepages_transparent
+// +build !nohugepages_transparent
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type thpConfigCollector struct {
+	enabledMode, defragMode, useZeroPage typedDesc
+	logger                               log.Logger
+}
+
+func init() {
+	registerCollector("hugepages_transparent", defaultDisabled, NewTHPConfigCollector)
+}
+
+// NewTHPConfigCollector returns a new Collector exposing the Transparent
+// Hugepage configuration.
+func NewTHPConfigCollector(logger log.Logger) (Collector, error) {
+	return &thpConfigCollector{
+		enabledMode: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "thp", "enabled_mode_info"),
+			"Transparent Hugepage mode, the active one has the value 1, all others 0.",
+			[]string{"mode"}, nil,
+		), prometheus.GaugeValue},
+		defragMode: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "thp", "defrag_mode_info"),
+			"Transparent Hugepage defrag mode, the active one has the value 1, all others 0.",
+			[]string{"mode"}, nil,
+		), prometheus.GaugeValue},
+		useZeroPage: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "thp", "use_zero_page"),
+			"Whether the huge zero page gets used for read page faults (1) or not (0).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the settings found in
+// /sys/kernel/mm/transparent_hugepage/. Settings missing on older kernels get
+// skipped.
+func (c *thpConfigCollector) Update(ch chan<- prometheus.Metric) error {
+	found := false
+	for _, setting := range []struct {
+		file string
+		desc typedDesc
+	}{
+		{"enabled", c.enabledMode},
+		{"defrag", c.defragMode},
+	} {
+		data, err := readStringFromFile(sysFilePath("kernel/mm/transparent_hugepage/" + setting.file))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if !found {
+				level.Debug(c.logger).Log("msg", "Kernel without THP support")
+				return ErrNoData
+			}
+			level.Debug(c.logger).Log("msg", "THP setting not found", "setting", setting.file)
+			continue
+		}
+		selected := parseSysfsSelection(data)
+		for _, mode := range strings.Fields(data) {
+			mode = strings.Trim(mode, "[]")
+			value := 0.0
+			if mode == selected {
+				value = 1
+			}
+			ch <- setting.desc.mustNewConstMetric(value, mode)
+		}
+		found = true
+	}
+
+	value, err := readUintFromFile(sysFilePath("kernel/mm/transparent_hugepage/use_zero_page"))
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Failed to read use_zero_page", "err", err)
+		return nil
+	}
+	ch <- c.useZeroPage.mustNewConstMetric(float64(value))
+	return nil
+}
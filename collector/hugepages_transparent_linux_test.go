@@ -0,0 +1,70 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nohugepages_transparent
+// +build !nohugepages_transparent
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTHPConfigCollector(t *testing.T) {
+	for _, tc := range []struct {
+		fixture, want string
+	}{
+		{"thp", `# HELP node_thp_defrag_mode_info Transparent Hugepage defrag mode, the active one has the value 1, all others 0.
+# TYPE node_thp_defrag_mode_info gauge
+node_thp_defrag_mode_info{mode="always"} 0
+node_thp_defrag_mode_info{mode="defer"} 0
+node_thp_defrag_mode_info{mode="defer+madvise"} 1
+node_thp_defrag_mode_info{mode="madvise"} 0
+node_thp_defrag_mode_info{mode="never"} 0
+# HELP node_thp_enabled_mode_info Transparent Hugepage mode, the active one has the value 1, all others 0.
+# TYPE node_thp_enabled_mode_info gauge
+node_thp_enabled_mode_info{mode="always"} 0
+node_thp_enabled_mode_info{mode="madvise"} 1
+node_thp_enabled_mode_info{mode="never"} 0
+# HELP node_thp_use_zero_page Whether the huge zero page gets used for read page faults (1) or not (0).
+# TYPE node_thp_use_zero_page gauge
+node_thp_use_zero_page 1
+`},
+		// kernels without defrag and use_zero_page
+		{"thp_partial", `# HELP node_thp_enabled_mode_info Transparent Hugepage mode, the active one has the value 1, all others 0.
+# TYPE node_thp_enabled_mode_info gauge
+node_thp_enabled_mode_info{mode="always"} 1
+node_thp_enabled_mode_info{mode="madvise"} 0
+node_thp_enabled_mode_info{mode="never"} 0
+`},
+	} {
+		t.Run(tc.fixture, func(t *testing.T) {
+			*sysPath = "fixtures/" + tc.fixture
+			c, err := NewTHPConfigCollector(log.NewNopLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Update(make(chan prometheus.Metric, 16)); err != nil {
+				t.Fatal(err)
+			}
+			if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(tc.want)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nohugetlb
+// +build !nohugetlb
+
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// hugeTlbStates maps the files of a hugepages-<size>kB directory to the
+// state label.
+var hugeTlbStates = []struct {
+	file, state string
+}{
+	{"nr_hugepages", "total"},
+	{"free_hugepages", "free"},
+	{"resv_hugepages", "reserved"},
+	{"surplus_hugepages", "surplus"},
+}
+
+type hugeTlbCollector struct {
+	pages  typedDesc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("hugetlb", defaultEnabled, NewHugeTLBCollector)
+}
+
+// NewHugeTLBCollector returns a new Collector exposing the system wide
+// HugeTLB pool of each supported huge page size. The per NUMA node pools
+// are exposed by the meminfo_numa collector.
+func NewHugeTLBCollector(logger log.Logger) (Collector, error) {
+	return &hugeTlbCollector{
+		pages: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "hugetlb", "pages"),
+			"Number of huge pages of the given size in the HugeTLB pool by state.",
+			[]string{"size_kb", "state"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Unlike /proc/meminfo, which covers the
+// default huge page size only, /sys/kernel/mm/hugepages/ has a directory
+// for each size (e.g. 2048kB and 1048576kB on x86_64).
+func (c *hugeTlbCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("kernel/mm/hugepages/hugepages-*kB"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "Kernel without HugeTLB support")
+		return ErrNoData
+	}
+	for _, dir := range dirs {
+		size := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "hugepages-"), "kB")
+		for _, s := range hugeTlbStates {
+			v, err := readUintFromFile(filepath.Join(dir, s.file))
+			if err != nil {
+				level.Debug(c.logger).Log("msg", "Failed to read huge page stats", "file", s.file, "size_kb", size, "err", err)
+				continue
+			}
+			ch <- c.pages.mustNewConstMetric(float64(v), size, s.state)
+		}
+	}
+	return nil
+}
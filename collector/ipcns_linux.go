@@ -0,0 +1,173 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noipcns
+// +build !noipcns
+
+package collector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+// ipcNsCounts are the number of message queues, semaphore sets and shared
+// memory segments of an IPC namespace.
+type ipcNsCounts struct {
+	msg, sem, shm int
+}
+
+type ipcNsCollector struct {
+	msg, sem, shm typedDesc
+	logger        log.Logger
+}
+
+func init() {
+	registerCollector("ipcns", defaultDisabled, NewIPCNsCollector)
+}
+
+// NewIPCNsCollector returns a new Collector exposing the System V IPC
+// objects of each IPC namespace in use.
+func NewIPCNsCollector(logger log.Logger) (Collector, error) {
+	desc := func(name, help string) typedDesc {
+		return typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ipcns", name),
+			help, []string{"ns_inode"}, nil,
+		), prometheus.GaugeValue}
+	}
+	return &ipcNsCollector{
+		msg:    desc("message_queues", "Number of System V message queues in the IPC namespace."),
+		sem:    desc("semaphores", "Number of System V semaphore sets in the IPC namespace."),
+		shm:    desc("shm_segments", "Number of System V shared memory segments in the IPC namespace."),
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. For each distinct IPC namespace of all
+// processes the exporter switches a locked OS thread into the namespace
+// via setns(2) and reads /proc/sysvipc/, which shows the objects of the
+// namespace of the reading thread. This requires CAP_SYS_ADMIN.
+func (c *ipcNsCollector) Update(ch chan<- prometheus.Metric) error {
+	links, err := filepath.Glob(procFilePath("[0-9]*/ns/ipc"))
+	if err != nil {
+		return err
+	}
+	namespaces := make(map[string]string)
+	for _, link := range links {
+		target, err := os.Readlink(link)
+		if err != nil {
+			continue
+		}
+		// ipc:[4026531839]
+		inode := strings.TrimSuffix(strings.TrimPrefix(target, "ipc:["), "]")
+		if _, ok := namespaces[inode]; !ok {
+			namespaces[inode] = link
+		}
+	}
+
+	// read all namespaces first, so that the result is known before
+	// anything gets sent
+	counts := make(map[string]ipcNsCounts, len(namespaces))
+	for inode, link := range namespaces {
+		n, err := readIPCNsCounts(link)
+		if err != nil {
+			if errors.Is(err, os.ErrPermission) || errors.Is(err, unix.EPERM) {
+				level.Debug(c.logger).Log("msg", "Not allowed to enter IPC namespaces, CAP_SYS_ADMIN required", "err", err)
+				break
+			}
+			level.Debug(c.logger).Log("msg", "Failed to read IPC namespace", "ns_inode", inode, "err", err)
+			continue
+		}
+		counts[inode] = n
+	}
+	if len(counts) == 0 {
+		return ErrNoData
+	}
+	for inode, n := range counts {
+		ch <- c.msg.mustNewConstMetric(float64(n.msg), inode)
+		ch <- c.sem.mustNewConstMetric(float64(n.sem), inode)
+		ch <- c.shm.mustNewConstMetric(float64(n.shm), inode)
+	}
+	return nil
+}
+
+// readIPCNsCounts reads the IPC object counts of the namespace referenced by
+// the given /proc/<pid>/ns/ipc link in a dedicated goroutine. If the thread
+// could not be switched back to the original namespace, it stays locked, so
+// the runtime terminates it when the goroutine exits.
+func readIPCNsCounts(link string) (ipcNsCounts, error) {
+	type result struct {
+		counts ipcNsCounts
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		runtime.LockOSThread()
+		counts, restored, err := readIPCNsCountsLocked(link)
+		if restored {
+			runtime.UnlockOSThread()
+		}
+		done <- result{counts, err}
+	}()
+	r := <-done
+	return r.counts, r.err
+}
+
+func readIPCNsCountsLocked(link string) (counts ipcNsCounts, restored bool, err error) {
+	self, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/ipc", unix.Gettid()))
+	if err != nil {
+		return counts, true, err
+	}
+	defer self.Close()
+	target, err := os.Open(link)
+	if err != nil {
+		return counts, true, err
+	}
+	defer target.Close()
+
+	if err := unix.Setns(int(target.Fd()), unix.CLONE_NEWIPC); err != nil {
+		return counts, true, err
+	}
+	for _, f := range []struct {
+		name  string
+		count *int
+	}{
+		{"msg", &counts.msg},
+		{"sem", &counts.sem},
+		{"shm", &counts.shm},
+	} {
+		var data []byte
+		if data, err = ioutil.ReadFile(procFilePath("sysvipc/" + f.name)); err != nil {
+			break
+		}
+		// the first line is the header
+		if n := bytes.Count(data, []byte{'\n'}); n > 0 {
+			*f.count = n - 1
+		}
+	}
+	if rerr := unix.Setns(int(self.Fd()), unix.CLONE_NEWIPC); rerr != nil {
+		return counts, false, fmt.Errorf("failed to restore IPC namespace: %w", rerr)
+	}
+	return counts, true, err
+}
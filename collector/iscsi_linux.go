@@ -0,0 +1,160 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noiscsi
+// +build !noiscsi
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const iscsiSubsystem = "iscsi"
+
+type iscsiCollector struct {
+	sessionInfo, connectionState, sessions typedDesc
+	ioRequests, ioDone, ioErrors           typedDesc
+	logger                                 log.Logger
+}
+
+func init() {
+	registerCollector("iscsi", defaultDisabled, NewIscsiCollector)
+}
+
+// NewIscsiCollector returns a new Collector exposing iSCSI session and
+// connection states.
+func NewIscsiCollector(logger log.Logger) (Collector, error) {
+	return &iscsiCollector{
+		sessionInfo: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, iscsiSubsystem, "session_info"),
+			"Non-numeric data from /sys/class/iscsi_session/<session>, value is always 1.",
+			[]string{"session", "target_name", "state"}, nil,
+		), prometheus.GaugeValue},
+		connectionState: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, iscsiSubsystem, "connection_state"),
+			"Whether the iSCSI connection is up (1) or not (0).",
+			[]string{"session", "connection"}, nil,
+		), prometheus.GaugeValue},
+		sessions: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, iscsiSubsystem, "sessions_total"),
+			"Number of iSCSI sessions.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		ioRequests: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, iscsiSubsystem, "lu_io_requests_total"),
+			"Number of I/O requests sent to the logical unit.",
+			[]string{"session", "device"}, nil,
+		), prometheus.CounterValue},
+		ioDone: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, iscsiSubsystem, "lu_io_done_total"),
+			"Number of I/O requests completed by the logical unit.",
+			[]string{"session", "device"}, nil,
+		), prometheus.CounterValue},
+		ioErrors: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, iscsiSubsystem, "lu_io_errors_total"),
+			"Number of I/O requests to the logical unit, which failed.",
+			[]string{"session", "device"}, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes iSCSI session and connection stats.
+func (c *iscsiCollector) Update(ch chan<- prometheus.Metric) error {
+	sessions, err := filepath.Glob(sysFilePath("class/iscsi_session/session*"))
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		level.Debug(c.logger).Log("msg", "No iSCSI sessions found")
+		return ErrNoData
+	}
+
+	for _, session := range sessions {
+		name := filepath.Base(session)
+		target, err := readStringFromFile(filepath.Join(session, "targetname"))
+		if err != nil {
+			return err
+		}
+		state, err := readStringFromFile(filepath.Join(session, "state"))
+		if err != nil {
+			return err
+		}
+		ch <- c.sessionInfo.mustNewConstMetric(1, name, target, state)
+
+		if err := c.updateLUStats(ch, session, name); err != nil {
+			return err
+		}
+	}
+	ch <- c.sessions.mustNewConstMetric(float64(len(sessions)))
+
+	// connection<sid>:<cid> belongs to session<sid>
+	connections, err := filepath.Glob(sysFilePath("class/iscsi_connection/connection*"))
+	if err != nil {
+		return err
+	}
+	for _, connection := range connections {
+		name := filepath.Base(connection)
+		state, err := readStringFromFile(filepath.Join(connection, "state"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// kernels < 5.5 do not export the connection state
+				continue
+			}
+			return err
+		}
+		session := "session" + strings.SplitN(strings.TrimPrefix(name, "connection"), ":", 2)[0]
+		up := 0.0
+		if state == "up" {
+			up = 1
+		}
+		ch <- c.connectionState.mustNewConstMetric(up, session, name)
+	}
+	return nil
+}
+
+// updateLUStats exposes the I/O counters of all SCSI devices attached to the
+// given session.
+func (c *iscsiCollector) updateLUStats(ch chan<- prometheus.Metric, session, name string) error {
+	devices, err := filepath.Glob(filepath.Join(session, "device", "target*", "*:*:*:*"))
+	if err != nil {
+		return err
+	}
+	for _, device := range devices {
+		hctl := filepath.Base(device)
+		for _, stat := range []struct {
+			file string
+			desc typedDesc
+		}{
+			{"iorequest_cnt", c.ioRequests},
+			{"iodone_cnt", c.ioDone},
+			{"ioerr_cnt", c.ioErrors},
+		} {
+			value, err := readSCSICounterFromFile(filepath.Join(device, stat.file))
+			if err != nil {
+				level.Debug(c.logger).Log("msg", "Failed to read SCSI counter", "device", hctl, "file", stat.file, "err", err)
+				continue
+			}
+			ch <- stat.desc.mustNewConstMetric(float64(value), name, hctl)
+		}
+	}
+	return nil
+}
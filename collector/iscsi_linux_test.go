@@ -0,0 +1,55 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noiscsi
+// +build !noiscsi
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestIscsiCollector(t *testing.T) {
+	*sysPath = "fixtures/iscsi"
+	c, err := NewIscsiCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_iscsi_connection_state Whether the iSCSI connection is up (1) or not (0).
+# TYPE node_iscsi_connection_state gauge
+node_iscsi_connection_state{connection="connection1:0",session="session1"} 1
+# HELP node_iscsi_lu_io_done_total Number of I/O requests completed by the logical unit.
+# TYPE node_iscsi_lu_io_done_total counter
+node_iscsi_lu_io_done_total{device="2:0:0:1",session="session1"} 417
+# HELP node_iscsi_lu_io_errors_total Number of I/O requests to the logical unit, which failed.
+# TYPE node_iscsi_lu_io_errors_total counter
+node_iscsi_lu_io_errors_total{device="2:0:0:1",session="session1"} 2
+# HELP node_iscsi_lu_io_requests_total Number of I/O requests sent to the logical unit.
+# TYPE node_iscsi_lu_io_requests_total counter
+node_iscsi_lu_io_requests_total{device="2:0:0:1",session="session1"} 419
+# HELP node_iscsi_session_info Non-numeric data from /sys/class/iscsi_session/<session>, value is always 1.
+# TYPE node_iscsi_session_info gauge
+node_iscsi_session_info{session="session1",state="LOGGED_IN",target_name="iqn.2003-01.org.linux-iscsi.storage:sn.0123456789ab"} 1
+# HELP node_iscsi_sessions_total Number of iSCSI sessions.
+# TYPE node_iscsi_sessions_total gauge
+node_iscsi_sessions_total 1
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nokeyring
+// +build !nokeyring
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type keyUser struct {
+	uid                            string
+	keys, maxKeys, bytes, maxBytes uint64
+}
+
+type keyringCollector struct {
+	keys, keysMax, bytes, bytesMax, visible typedDesc
+	logger                                  log.Logger
+}
+
+func init() {
+	registerCollector("keyring", defaultDisabled, NewKeyringCollector)
+}
+
+// NewKeyringCollector returns a new Collector exposing the kernel key
+// quota usage per user.
+func NewKeyringCollector(logger log.Logger) (Collector, error) {
+	desc := func(name, help string, labels []string) typedDesc {
+		return typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "keyring", name),
+			help, labels, nil,
+		), prometheus.GaugeValue}
+	}
+	uid := []string{"uid"}
+	return &keyringCollector{
+		keys:     desc("keys", "Number of keys owned by the user.", uid),
+		keysMax:  desc("keys_max", "Maximum number of keys the user may own.", uid),
+		bytes:    desc("bytes", "Number of bytes of key payload owned by the user.", uid),
+		bytesMax: desc("bytes_max", "Maximum number of bytes of key payload the user may own.", uid),
+		visible:  desc("visible_keys", "Number of keys listed in /proc/keys, i.e. keys the exporter is allowed to view.", nil),
+		logger:   logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *keyringCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("key-users"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Kernel without key retention support")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	users, err := parseKeyUsers(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for _, u := range users {
+		ch <- c.keys.mustNewConstMetric(float64(u.keys), u.uid)
+		ch <- c.keysMax.mustNewConstMetric(float64(u.maxKeys), u.uid)
+		ch <- c.bytes.mustNewConstMetric(float64(u.bytes), u.uid)
+		ch <- c.bytesMax.mustNewConstMetric(float64(u.maxBytes), u.uid)
+	}
+
+	// before Linux 4.15 /proc/keys required CONFIG_KEYS_DEBUG_PROC_KEYS
+	data, err := ioutil.ReadFile(procFilePath("keys"))
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Failed to read /proc/keys", "err", err)
+		return nil
+	}
+	ch <- c.visible.mustNewConstMetric(float64(bytes.Count(data, []byte{'\n'})))
+	return nil
+}
+
+// parseKeyUsers parses /proc/key-users, lines look like
+// "    0:    94 93/93 85/1000000 2135/25000000", i.e. uid, usage, keys and
+// instantiated keys, quota keys/max keys and quota bytes/max bytes.
+func parseKeyUsers(r io.Reader) ([]keyUser, error) {
+	var users []keyUser
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 5 {
+			continue
+		}
+		keys, maxKeys, err := parseKeyUsersPair(fields[3])
+		if err != nil {
+			return nil, err
+		}
+		bytes, maxBytes, err := parseKeyUsersPair(fields[4])
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, keyUser{
+			uid:      strings.TrimSuffix(fields[0], ":"),
+			keys:     keys,
+			maxKeys:  maxKeys,
+			bytes:    bytes,
+			maxBytes: maxBytes,
+		})
+	}
+	return users, scanner.Err()
+}
+
+func parseKeyUsersPair(s string) (uint64, uint64, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid value %q", s)
+	}
+	a, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := strconv.ParseUint(parts[1], 10, 64)
+	return a, b, err
+}
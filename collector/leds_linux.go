@@ -0,0 +1,112 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noleds
+// +build !noleds
+
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var ledsInclude = kingpin.Flag("collector.leds.include", "Regexp of LED names to include.").Default(".*").String()
+
+type ledsCollector struct {
+	brightness, maxBrightness, ratio typedDesc
+	pattern                          *regexp.Regexp
+	logger                           log.Logger
+}
+
+func init() {
+	registerCollector("leds", defaultDisabled, NewLedsCollector)
+}
+
+// NewLedsCollector returns a new Collector exposing LED brightness levels.
+func NewLedsCollector(logger log.Logger) (Collector, error) {
+	pattern, err := regexp.Compile(*ledsInclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --collector.leds.include: %w", err)
+	}
+	return &ledsCollector{
+		brightness: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "led", "brightness"),
+			"Current brightness of the LED.",
+			[]string{"name", "trigger"}, nil,
+		), prometheus.GaugeValue},
+		maxBrightness: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "led", "max_brightness"),
+			"Maximum brightness of the LED.",
+			[]string{"name"}, nil,
+		), prometheus.GaugeValue},
+		ratio: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "led", "brightness_ratio"),
+			"Current brightness of the LED relative to its maximum brightness (0.0 - 1.0).",
+			[]string{"name"}, nil,
+		), prometheus.GaugeValue},
+		pattern: pattern,
+		logger:  logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the LEDs found in /sys/class/leds/.
+func (c *ledsCollector) Update(ch chan<- prometheus.Metric) error {
+	leds, err := filepath.Glob(sysFilePath("class/leds/*"))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, led := range leds {
+		name := filepath.Base(led)
+		if !c.pattern.MatchString(name) {
+			continue
+		}
+		brightness, err := readUintFromFile(filepath.Join(led, "brightness"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read LED brightness", "led", name, "err", err)
+			continue
+		}
+		maxBrightness, err := readUintFromFile(filepath.Join(led, "max_brightness"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read LED max_brightness", "led", name, "err", err)
+			continue
+		}
+		// e.g. "none rfkill-any [heartbeat] timer", missing if the kernel
+		// has no LED trigger support
+		var trigger string
+		if data, err := ioutil.ReadFile(filepath.Join(led, "trigger")); err == nil {
+			trigger = parseSysfsSelection(string(data))
+		}
+
+		ch <- c.brightness.mustNewConstMetric(float64(brightness), name, trigger)
+		ch <- c.maxBrightness.mustNewConstMetric(float64(maxBrightness), name)
+		if maxBrightness > 0 {
+			ch <- c.ratio.mustNewConstMetric(float64(brightness)/float64(maxBrightness), name)
+		}
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No LED devices found")
+		return ErrNoData
+	}
+	return nil
+}
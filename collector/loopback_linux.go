@@ -0,0 +1,98 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noloopback
+// +build !noloopback
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const loopbackSubsystem = "loopback"
+
+type loopbackCollector struct {
+	info, active typedDesc
+	logger       log.Logger
+}
+
+func init() {
+	registerCollector("loopback", defaultDisabled, NewLoopbackCollector)
+}
+
+// NewLoopbackCollector returns a new Collector exposing configured loop
+// devices.
+func NewLoopbackCollector(logger log.Logger) (Collector, error) {
+	return &loopbackCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, loopbackSubsystem, "info"),
+			"Non-numeric data from /sys/block/<device>/loop, value is always 1.",
+			[]string{"device", "backing_file", "dio", "autoclear"}, nil,
+		), prometheus.GaugeValue},
+		active: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, loopbackSubsystem, "active_total"),
+			"Number of configured loop devices.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes all loop devices with a backing
+// file.
+func (c *loopbackCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := filepath.Glob(sysFilePath("block/loop*"))
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		level.Debug(c.logger).Log("msg", "No loop devices found")
+		return ErrNoData
+	}
+
+	active := 0
+	for _, device := range devices {
+		// The loop directory exists for configured devices only.
+		dir := filepath.Join(device, "loop")
+		backingFile, err := readStringFromFile(filepath.Join(dir, "backing_file"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		if backingFile == "" {
+			continue
+		}
+		values := []string{filepath.Base(device), backingFile}
+		for _, attr := range []string{"dio", "autoclear"} {
+			// dio is not available on kernels < 4.4
+			v, err := readStringFromFile(filepath.Join(dir, attr))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			values = append(values, v)
+		}
+		ch <- c.info.mustNewConstMetric(1, values...)
+		active++
+	}
+	ch <- c.active.mustNewConstMetric(float64(active))
+	return nil
+}
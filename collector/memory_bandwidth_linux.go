@@ -0,0 +1,139 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nomemory_bandwidth
+// +build !nomemory_bandwidth
+
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Each CAS (column address strobe) command transfers one 64 byte cache line.
+const memoryBandwidthCASBytes = 64
+
+type memoryBandwidthCounter struct {
+	pmuCounter
+	write bool
+}
+
+type memoryBandwidthCollector struct {
+	read, write typedDesc
+	counters    []memoryBandwidthCounter
+	logger      log.Logger
+}
+
+func init() {
+	registerCollector("memory_bandwidth", defaultDisabled, NewMemoryBandwidthCollector)
+}
+
+// NewMemoryBandwidthCollector returns a new Collector exposing the memory
+// bandwidth per socket using the uncore IMC (integrated memory controller)
+// PMUs of Intel Xeon CPUs. Requires CAP_PERFMON (or CAP_SYS_ADMIN) or
+// kernel.perf_event_paranoid <= 0.
+func NewMemoryBandwidthCollector(logger log.Logger) (Collector, error) {
+	c := &memoryBandwidthCollector{
+		read: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory_bandwidth", "read_bytes_total"),
+			"Number of bytes read from memory by the memory controllers of the socket.",
+			[]string{"socket"}, nil,
+		), prometheus.CounterValue},
+		write: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory_bandwidth", "write_bytes_total"),
+			"Number of bytes written to memory by the memory controllers of the socket.",
+			[]string{"socket"}, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}
+
+	pmus, err := filepath.Glob(sysFilePath("bus/event_source/devices/uncore_imc_*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, pmu := range pmus {
+		if err := c.openPMU(pmu); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(pmu), err)
+		}
+	}
+	return c, nil
+}
+
+// openPMU opens the cas_count_read and cas_count_write events of the given
+// PMU on one CPU of each socket listed in its cpumask.
+func (c *memoryBandwidthCollector) openPMU(pmu string) error {
+	for _, event := range []struct {
+		name  string
+		write bool
+	}{
+		{"cas_count_read", false},
+		{"cas_count_write", true},
+	} {
+		config, err := parsePMUEventConfig(pmu, event.name)
+		if err != nil {
+			return err
+		}
+		counters, err := openUncorePMUCounters(pmu, config)
+		if err != nil {
+			return err
+		}
+		for _, counter := range counters {
+			c.counters = append(c.counters, memoryBandwidthCounter{counter, event.write})
+		}
+	}
+	return nil
+}
+
+// Close implements closer.
+func (c *memoryBandwidthCollector) Close() error {
+	for _, counter := range c.counters {
+		counter.close()
+	}
+	c.counters = nil
+	return nil
+}
+
+// Update implements Collector and exposes the memory bandwidth counters
+// summed up per socket.
+func (c *memoryBandwidthCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.counters) == 0 {
+		level.Debug(c.logger).Log("msg", "No uncore IMC PMUs found")
+		return ErrNoData
+	}
+
+	read, write := map[string]uint64{}, map[string]uint64{}
+	for _, counter := range c.counters {
+		value, err := readPMUCounter(counter.pmuCounter)
+		if err != nil {
+			return err
+		}
+		if counter.write {
+			write[counter.socket] += value
+		} else {
+			read[counter.socket] += value
+		}
+	}
+	for socket, value := range read {
+		ch <- c.read.mustNewConstMetric(float64(value*memoryBandwidthCASBytes), socket)
+	}
+	for socket, value := range write {
+		ch <- c.write.mustNewConstMetric(float64(value*memoryBandwidthCASBytes), socket)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nomfd
+// +build !nomfd
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type mfdCollector struct {
+	info, total typedDesc
+	logger      log.Logger
+}
+
+func init() {
+	registerCollector("mfd", defaultDisabled, NewMFDCollector)
+}
+
+// NewMFDCollector returns a new Collector exposing the sub-devices of
+// multi-function devices.
+func NewMFDCollector(logger log.Logger) (Collector, error) {
+	return &mfdCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mfd", "device_info"),
+			"Sub-device of a multi-function device.",
+			[]string{"parent", "name", "id"}, nil,
+		), prometheus.GaugeValue},
+		total: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mfd", "devices_total"),
+			"Number of multi-function sub-devices.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *mfdCollector) Update(ch chan<- prometheus.Metric) error {
+	if _, err := os.Stat(sysFilePath("bus/platform/devices")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "No platform bus found")
+			return ErrNoData
+		}
+		return err
+	}
+	devices, err := filepath.Glob(sysFilePath("bus/platform/devices/*/mfd/*"))
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		parent := filepath.Base(filepath.Dir(filepath.Dir(device)))
+		name, id := splitMFDDeviceName(filepath.Base(device))
+		ch <- c.info.mustNewConstMetric(1, parent, name, id)
+	}
+	ch <- c.total.mustNewConstMetric(float64(len(devices)))
+	return nil
+}
+
+// splitMFDDeviceName splits platform device names like "twl4030-usb.0" into
+// name and instance id. Names without a numeric suffix have no id.
+func splitMFDDeviceName(device string) (string, string) {
+	i := strings.LastIndexByte(device, '.')
+	if i < 0 {
+		return device, ""
+	}
+	if _, err := strconv.Atoi(device[i+1:]); err != nil {
+		return device, ""
+	}
+	return device[:i], device[i+1:]
+}
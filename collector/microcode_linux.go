@@ -0,0 +1,112 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nomicrocode
+// +build !nomicrocode
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const microcodeSubsystem = "microcode"
+
+type microcodeCollector struct {
+	version, updateAvailable typedDesc
+	logger                   log.Logger
+}
+
+func init() {
+	registerCollector("microcode", defaultDisabled, NewMicrocodeCollector)
+}
+
+// NewMicrocodeCollector returns a new Collector exposing the microcode
+// version of each CPU package.
+func NewMicrocodeCollector(logger log.Logger) (Collector, error) {
+	return &microcodeCollector{
+		version: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, microcodeSubsystem, "version"),
+			"Microcode version of the first logical CPU of the package.",
+			[]string{"package"}, nil,
+		), prometheus.GaugeValue},
+		updateAvailable: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, microcodeSubsystem, "update_available"),
+			"Whether a microcode update is available (1) or not (0). Currently always 0.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Unlike cpu_info, which gets cached on start,
+// the version gets read on every scrape, so late microcode loads show up.
+func (c *microcodeCollector) Update(ch chan<- prometheus.Metric) error {
+	cpus, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*"))
+	if err != nil {
+		return err
+	}
+
+	// package -> lowest CPU number seen
+	first := map[string]int{}
+	for _, cpu := range cpus {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(cpu), "cpu"))
+		if err != nil {
+			continue
+		}
+		pkg, err := readStringFromFile(filepath.Join(cpu, "topology", "physical_package_id"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// offline CPU
+				continue
+			}
+			return err
+		}
+		if m, ok := first[pkg]; !ok || n < m {
+			first[pkg] = n
+		}
+	}
+
+	found := false
+	for pkg, n := range first {
+		file := sysFilePath(fmt.Sprintf("devices/system/cpu/cpu%d/microcode/version", n))
+		data, err := readStringFromFile(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		version, err := strconv.ParseUint(data, 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid microcode version in %s: %w", file, err)
+		}
+		ch <- c.version.mustNewConstMetric(float64(version), pkg)
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No CPU microcode version found")
+		return ErrNoData
+	}
+	ch <- c.updateAvailable.mustNewConstMetric(0)
+	return nil
+}
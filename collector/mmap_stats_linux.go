@@ -0,0 +1,181 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nommap_stats
+// +build !nommap_stats
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var mmapStatsPID = kingpin.Flag("collector.mmap_stats.pid", "PID of the process whose memory mappings should be reported, empty means all processes.").Default("").String()
+
+type mmapStats struct {
+	count, bytes map[string]uint64
+}
+
+type mmapStatsCollector struct {
+	count, bytes, max, processMax typedDesc
+	pid                           string
+	logger                        log.Logger
+}
+
+func init() {
+	registerCollector("mmap_stats", defaultDisabled, NewMmapStatsCollector)
+}
+
+// NewMmapStatsCollector returns a new Collector exposing the virtual memory
+// areas (VMAs) of a single or all processes by type.
+func NewMmapStatsCollector(logger log.Logger) (Collector, error) {
+	pid := *mmapStatsPID
+	if pid == "self" {
+		pid = readProcSelf()
+	} else if pid != "" {
+		if _, err := strconv.ParseUint(pid, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid --collector.mmap_stats.pid %q", pid)
+		}
+	}
+	return &mmapStatsCollector{
+		count: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mmap", "vma_count"),
+			"Number of virtual memory areas by type.",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		bytes: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mmap", "total_bytes"),
+			"Size of the virtual memory areas by type.",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		max: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mmap", "max_entries"),
+			"Maximum number of virtual memory areas a process may have (vm.max_map_count).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		processMax: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mmap", "process_vma_count_max"),
+			"Highest number of virtual memory areas of a single process.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		pid:    pid,
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Since vm.max_map_count is a per process
+// limit, the VMA count of the process having the most VMAs gets exposed as
+// well, so one is able to alert on processes close to the limit.
+func (c *mmapStatsCollector) Update(ch chan<- prometheus.Metric) error {
+	pattern := "[0-9]*"
+	if c.pid != "" {
+		pattern = c.pid
+	}
+	files, err := filepath.Glob(procFilePath(pattern + "/maps"))
+	if err != nil {
+		return err
+	}
+
+	stats := mmapStats{count: map[string]uint64{}, bytes: map[string]uint64{}}
+	processMax := uint64(0)
+	found := false
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			// process is gone or not accessible
+			continue
+		}
+		n, err := parseProcMaps(f, stats)
+		f.Close()
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to parse maps", "file", file, "err", err)
+			continue
+		}
+		if n > processMax {
+			processMax = n
+		}
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No readable maps found")
+		return ErrNoData
+	}
+	for typ, value := range stats.count {
+		ch <- c.count.mustNewConstMetric(float64(value), typ)
+		ch <- c.bytes.mustNewConstMetric(float64(stats.bytes[typ]), typ)
+	}
+	ch <- c.processMax.mustNewConstMetric(float64(processMax))
+
+	max, err := readUintFromFile(procFilePath("sys/vm/max_map_count"))
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Failed to read max_map_count", "err", err)
+		return nil
+	}
+	ch <- c.max.mustNewConstMetric(float64(max))
+	return nil
+}
+
+// parseProcMaps adds the VMAs of the given maps content to stats and returns
+// their number. Lines look like
+// "7f0e4c000000-7f0e4c021000 rw-p 00000000 00:00 0    [heap]". Mappings
+// without a path or with a pseudo path other than [heap] and [stack] (e.g.
+// [vdso] or [anon:name]) count as anon.
+func parseProcMaps(r io.Reader, stats mmapStats) (uint64, error) {
+	n := uint64(0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		bounds := strings.SplitN(fields[0], "-", 2)
+		if len(bounds) != 2 {
+			return n, fmt.Errorf("invalid address range %q", fields[0])
+		}
+		start, err := strconv.ParseUint(bounds[0], 16, 64)
+		if err != nil {
+			return n, err
+		}
+		end, err := strconv.ParseUint(bounds[1], 16, 64)
+		if err != nil {
+			return n, err
+		}
+
+		typ := "anon"
+		if len(fields) > 5 {
+			switch path := fields[5]; {
+			case path == "[heap]":
+				typ = "heap"
+			case strings.HasPrefix(path, "[stack"):
+				typ = "stack"
+			case strings.HasPrefix(path, "/"):
+				typ = "file"
+			}
+		}
+		stats.count[typ]++
+		stats.bytes[typ] += end - start
+		n++
+	}
+	return n, scanner.Err()
+}
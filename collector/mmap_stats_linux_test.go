@@ -0,0 +1,47 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nommap_stats
+// +build !nommap_stats
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProcMaps(t *testing.T) {
+	stats := mmapStats{count: map[string]uint64{}, bytes: map[string]uint64{}}
+	n, err := parseProcMaps(strings.NewReader(`55d4c8a00000-55d4c8a02000 r--p 00000000 fd:01 1835023                    /usr/bin/cat
+55d4c8a02000-55d4c8a07000 r-xp 00002000 fd:01 1835023                    /usr/bin/cat
+55d4ca0b1000-55d4ca0d2000 rw-p 00000000 00:00 0                          [heap]
+7f5c1e400000-7f5c1e6e9000 rw-p 00000000 00:00 0 
+7ffd0a3e1000-7ffd0a402000 rw-p 00000000 00:00 0                          [stack]
+7ffd0a5e8000-7ffd0a5ea000 r-xp 00000000 00:00 0                          [vdso]
+`), stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("want 6 VMAs, got %d", n)
+	}
+	if want := map[string]uint64{"file": 2, "heap": 1, "anon": 2, "stack": 1}; !reflect.DeepEqual(want, stats.count) {
+		t.Errorf("want count %v, got %v", want, stats.count)
+	}
+	want := map[string]uint64{"file": 0x7000, "heap": 0x21000, "anon": 0x2e9000 + 0x2000, "stack": 0x21000}
+	if !reflect.DeepEqual(want, stats.bytes) {
+		t.Errorf("want bytes %v, got %v", want, stats.bytes)
+	}
+}
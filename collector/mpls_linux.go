@@ -0,0 +1,189 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nompls
+// +build !nompls
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	mplsSubsystem = "mpls"
+
+	// sizeof(struct if_stats_msg), which precedes the attributes of
+	// RTM_NEWSTATS messages.
+	ifStatsMsgLen = 12
+	// MPLS_STATS_LINK attribute of the AF_MPLS stats, a struct
+	// mpls_link_stats.
+	mplsStatsLink = 1
+)
+
+// mplsStats lists the struct mpls_link_stats members to expose by their
+// index.
+var mplsStats = []struct {
+	index      int
+	name, help string
+}{
+	{0, "in_packets_total", "Number of MPLS packets received by the interface."},
+	{2, "in_bytes_total", "Number of MPLS bytes received by the interface."},
+	{1, "out_packets_total", "Number of MPLS packets sent by the interface."},
+	{3, "out_bytes_total", "Number of MPLS bytes sent by the interface."},
+}
+
+type mplsCollector struct {
+	descs          []*prometheus.Desc
+	platformLabels *prometheus.Desc
+	logger         log.Logger
+}
+
+func init() {
+	registerCollector("mpls", defaultEnabled, NewMPLSCollector)
+}
+
+// NewMPLSCollector returns a new Collector exposing MPLS interface stats.
+func NewMPLSCollector(logger log.Logger) (Collector, error) {
+	descs := make([]*prometheus.Desc, len(mplsStats))
+	for i, stat := range mplsStats {
+		descs[i] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, mplsSubsystem, stat.name),
+			stat.help, []string{"device"}, nil,
+		)
+	}
+	return &mplsCollector{
+		descs: descs,
+		platformLabels: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, mplsSubsystem, "platform_labels"),
+			"Size of the platform wide MPLS label space.",
+			nil, nil,
+		),
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes MPLS stats. The kernel provides
+// them per interface via rtnetlink (RTM_GETSTATS, IFLA_STATS_AF_SPEC) only,
+// for interfaces with MPLS input enabled.
+func (c *mplsCollector) Update(ch chan<- prometheus.Metric) error {
+	labels, err := readUintFromFile(procFilePath("sys/net/mpls/platform_labels"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "MPLS not available, module mpls_router loaded?")
+			return ErrNoData
+		}
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(c.platformLabels, prometheus.GaugeValue, float64(labels))
+
+	conn, err := netlink.Dial(unix.NETLINK_ROUTE, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open rtnetlink socket: %w", err)
+	}
+	defer conn.Close()
+
+	req := make([]byte, ifStatsMsgLen)
+	nlenc.PutUint32(req[8:], 1<<(unix.IFLA_STATS_AF_SPEC-1))
+	msgs, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  unix.RTM_GETSTATS,
+			Flags: netlink.Request | netlink.Dump,
+		},
+		Data: req,
+	})
+	if err != nil {
+		return fmt.Errorf("RTM_GETSTATS failed: %w", err)
+	}
+
+	stats, err := parseMPLSStats(msgs)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		return nil
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return err
+	}
+	for _, iface := range ifaces {
+		values, ok := stats[uint32(iface.Index)]
+		if !ok {
+			continue
+		}
+		for i, stat := range mplsStats {
+			ch <- prometheus.MustNewConstMetric(c.descs[i], prometheus.CounterValue, float64(values[stat.index]), iface.Name)
+		}
+	}
+	return nil
+}
+
+// parseMPLSStats returns the struct mpls_link_stats members of the given
+// RTM_NEWSTATS messages by interface index.
+func parseMPLSStats(msgs []netlink.Message) (map[uint32][]uint64, error) {
+	stats := map[uint32][]uint64{}
+	for _, m := range msgs {
+		if m.Header.Type != unix.RTM_NEWSTATS || len(m.Data) < ifStatsMsgLen {
+			continue
+		}
+		ad, err := netlink.NewAttributeDecoder(m.Data[ifStatsMsgLen:])
+		if err != nil {
+			return nil, err
+		}
+		for ad.Next() {
+			if ad.Type() != unix.IFLA_STATS_AF_SPEC {
+				continue
+			}
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					if nad.Type() != unix.AF_MPLS {
+						continue
+					}
+					nad.Nested(func(mad *netlink.AttributeDecoder) error {
+						for mad.Next() {
+							if mad.Type() != mplsStatsLink {
+								continue
+							}
+							b := mad.Bytes()
+							values := make([]uint64, len(b)/8)
+							for i := range values {
+								values[i] = nlenc.Uint64(b[i*8 : i*8+8])
+							}
+							if len(values) < 4 {
+								return fmt.Errorf("short MPLS link stats: %d bytes", len(b))
+							}
+							stats[nlenc.Uint32(m.Data[4:8])] = values
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+		}
+		if err := ad.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
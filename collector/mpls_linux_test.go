@@ -0,0 +1,95 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nompls
+// +build !nompls
+
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseMPLSStats(t *testing.T) {
+	newStatsMsg := func(index uint32, fn func(ae *netlink.AttributeEncoder)) netlink.Message {
+		ae := netlink.NewAttributeEncoder()
+		fn(ae)
+		attrs, err := ae.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := make([]byte, ifStatsMsgLen)
+		nlenc.PutUint32(data[4:8], index)
+		return netlink.Message{
+			Header: netlink.Header{Type: unix.RTM_NEWSTATS},
+			Data:   append(data, attrs...),
+		}
+	}
+	mplsLinkStats := func(values ...uint64) []byte {
+		var b []byte
+		for _, v := range values {
+			b = append(b, nlenc.Uint64Bytes(v)...)
+		}
+		return b
+	}
+
+	msgs := []netlink.Message{
+		// MPLS enabled interface
+		newStatsMsg(2, func(ae *netlink.AttributeEncoder) {
+			ae.Nested(unix.IFLA_STATS_AF_SPEC, func(nae *netlink.AttributeEncoder) error {
+				nae.Nested(unix.AF_MPLS, func(mae *netlink.AttributeEncoder) error {
+					mae.Bytes(mplsStatsLink, mplsLinkStats(10, 20, 1000, 2000, 0, 0, 1, 2, 3))
+					return nil
+				})
+				return nil
+			})
+		}),
+		// interface without MPLS
+		newStatsMsg(3, func(ae *netlink.AttributeEncoder) {
+			ae.Nested(unix.IFLA_STATS_AF_SPEC, func(nae *netlink.AttributeEncoder) error {
+				return nil
+			})
+		}),
+		// other stats
+		newStatsMsg(4, func(ae *netlink.AttributeEncoder) {
+			ae.Bytes(unix.IFLA_STATS_LINK_64, make([]byte, 8))
+		}),
+	}
+
+	stats, err := parseMPLSStats(msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint32][]uint64{2: {10, 20, 1000, 2000, 0, 0, 1, 2, 3}}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("want %v, got %v", want, stats)
+	}
+
+	short := newStatsMsg(2, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(unix.IFLA_STATS_AF_SPEC, func(nae *netlink.AttributeEncoder) error {
+			nae.Nested(unix.AF_MPLS, func(mae *netlink.AttributeEncoder) error {
+				mae.Bytes(mplsStatsLink, mplsLinkStats(10, 20))
+				return nil
+			})
+			return nil
+		})
+	})
+	if _, err := parseMPLSStats([]netlink.Message{short}); err == nil {
+		t.Error("expected error for short MPLS link stats")
+	}
+}
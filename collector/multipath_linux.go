@@ -0,0 +1,136 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nomultipath
+// +build !nomultipath
+
+package collector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const multipathSubsystem = "multipath"
+
+type multipathCollector struct {
+	info, suspended, paths, activePaths, mergeDeadline typedDesc
+	logger                                             log.Logger
+}
+
+func init() {
+	registerCollector("multipath", defaultDisabled, NewMultipathCollector)
+}
+
+// NewMultipathCollector returns a new Collector exposing device mapper
+// multipath stats.
+func NewMultipathCollector(logger log.Logger) (Collector, error) {
+	labels := []string{"dm_device"}
+	return &multipathCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, multipathSubsystem, "info"),
+			"Non-numeric data from /sys/class/block/<dm_device>/dm, value is always 1.",
+			[]string{"dm_device", "name", "uuid"}, nil,
+		), prometheus.GaugeValue},
+		suspended: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, multipathSubsystem, "suspended"),
+			"Whether the multipath device is suspended (1) or not (0).",
+			labels, nil,
+		), prometheus.GaugeValue},
+		paths: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, multipathSubsystem, "paths"),
+			"Number of paths (underlying block devices) of the multipath device.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		activePaths: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, multipathSubsystem, "active_paths"),
+			"Number of paths of the multipath device, whose SCSI device is running.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		mergeDeadline: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, multipathSubsystem, "seq_io_merge_deadline_seconds"),
+			"Deadline for merging sequential I/Os of request based multipath devices.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes device mapper multipath stats.
+func (c *multipathCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := filepath.Glob(sysFilePath("class/block/dm-*"))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, device := range devices {
+		dmDevice := filepath.Base(device)
+		uuid, err := readStringFromFile(filepath.Join(device, "dm", "uuid"))
+		if err != nil {
+			return err
+		}
+		// Other dm targets like LVM or crypt have a different uuid prefix.
+		if !strings.HasPrefix(uuid, "mpath-") {
+			continue
+		}
+		found = true
+
+		name, err := readStringFromFile(filepath.Join(device, "dm", "name"))
+		if err != nil {
+			return err
+		}
+		ch <- c.info.mustNewConstMetric(1, dmDevice, name, uuid)
+
+		suspended, err := readUintFromFile(filepath.Join(device, "dm", "suspended"))
+		if err != nil {
+			return err
+		}
+		ch <- c.suspended.mustNewConstMetric(float64(suspended), dmDevice)
+
+		// Paths are the devices the multipath device is stacked on, i.e.
+		// its slaves - holders would be devices stacked on top of it.
+		slaves, err := ioutil.ReadDir(filepath.Join(device, "slaves"))
+		if err != nil {
+			return err
+		}
+		active := 0
+		for _, slave := range slaves {
+			state, err := readStringFromFile(sysFilePath(filepath.Join("class/block", slave.Name(), "device", "state")))
+			if err != nil {
+				level.Debug(c.logger).Log("msg", "Failed to read path state", "dm_device", dmDevice, "path", slave.Name(), "err", err)
+				continue
+			}
+			if state == "running" {
+				active++
+			}
+		}
+		ch <- c.paths.mustNewConstMetric(float64(len(slaves)), dmDevice)
+		ch <- c.activePaths.mustNewConstMetric(float64(active), dmDevice)
+
+		// µs, available for request based devices, only.
+		if deadline, err := readUintFromFile(filepath.Join(device, "dm", "rq_based_seq_io_merge_deadline")); err == nil {
+			ch <- c.mergeDeadline.mustNewConstMetric(float64(deadline)/1e6, dmDevice)
+		}
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No multipath devices found")
+		return ErrNoData
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonet_dev_summary && !nonetdev
+// +build !nonet_dev_summary,!nonetdev
+
+package collector
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var netDevSummaryInclude = kingpin.Flag("collector.net-summary.include", "Regexp of net devices to include in the aggregated network device stats (default: all).").String()
+
+type netDevSummaryCollector struct {
+	deviceFilter netDevFilter
+	metricDescs  map[string]*prometheus.Desc
+	logger       log.Logger
+}
+
+func init() {
+	registerCollector("net_dev_summary", defaultDisabled, NewNetDevSummaryCollector)
+}
+
+// NewNetDevSummaryCollector returns a new Collector exposing the network
+// device stats summed up over all matching devices.
+func NewNetDevSummaryCollector(logger log.Logger) (Collector, error) {
+	if *netDevSummaryInclude != "" {
+		level.Info(logger).Log("msg", "Parsed flag --collector.net-summary.include", "flag", *netDevSummaryInclude)
+	}
+	return &netDevSummaryCollector{
+		deviceFilter: newNetDevFilter("", *netDevSummaryInclude),
+		metricDescs:  map[string]*prometheus.Desc{},
+		logger:       logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *netDevSummaryCollector) Update(ch chan<- prometheus.Metric) error {
+	netDev, err := getNetDevStats(&c.deviceFilter, c.logger)
+	if err != nil {
+		return fmt.Errorf("couldn't get netstats: %w", err)
+	}
+	sums := map[string]uint64{}
+	for _, devStats := range netDev {
+		for key, value := range devStats {
+			sums[key] += value
+		}
+	}
+	for key, value := range sums {
+		desc, ok := c.metricDescs[key]
+		if !ok {
+			desc = prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "network_aggregate", key+"_total"),
+				fmt.Sprintf("Network device statistic %s summed up over all included devices.", key),
+				nil, nil,
+			)
+			c.metricDescs[key] = desc
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value))
+	}
+	return nil
+}
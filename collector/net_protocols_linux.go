@@ -0,0 +1,141 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonet_protocols
+// +build !nonet_protocols
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type netProtocolStats struct {
+	protocol, module string
+	sockets          int64
+	// -1 if not implemented by the protocol
+	pressure int
+}
+
+type netProtocolsCollector struct {
+	sockets, pressure typedDesc
+	logger            log.Logger
+}
+
+func init() {
+	registerCollector("net_protocols", defaultDisabled, NewNetProtocolsCollector)
+}
+
+// NewNetProtocolsCollector returns a new Collector exposing the stats of all
+// registered network protocols.
+func NewNetProtocolsCollector(logger log.Logger) (Collector, error) {
+	return &netProtocolsCollector{
+		sockets: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "net_protocol", "sockets_count"),
+			"Number of sockets in use by the protocol.",
+			[]string{"protocol", "module"}, nil,
+		), prometheus.GaugeValue},
+		pressure: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "net_protocol", "memory_pressure"),
+			"Whether the protocol is under memory pressure (1) or not (0).",
+			[]string{"protocol"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes /proc/net/protocols.
+func (c *netProtocolsCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/protocols"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Kernel without /proc/net/protocols")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	protocols, err := parseNetProtocols(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for _, p := range protocols {
+		ch <- c.sockets.mustNewConstMetric(float64(p.sockets), p.protocol, p.module)
+		if p.pressure >= 0 {
+			ch <- c.pressure.mustNewConstMetric(float64(p.pressure), p.protocol)
+		}
+	}
+	return nil
+}
+
+// parseNetProtocols parses the given /proc/net/protocols content. The
+// columns are looked up by name using the header line, because their order
+// and number changed over time.
+func parseNetProtocols(r io.Reader) ([]netProtocolStats, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("empty file")
+	}
+	idx := map[string]int{}
+	for i, name := range strings.Fields(scanner.Text()) {
+		idx[name] = i
+	}
+	for _, name := range []string{"protocol", "sockets", "press", "module"} {
+		if _, ok := idx[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var protocols []netProtocolStats
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < len(idx) {
+			return nil, fmt.Errorf("unexpected number of fields in line %q", scanner.Text())
+		}
+		sockets, err := strconv.ParseInt(fields[idx["sockets"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sockets value in line %q: %w", scanner.Text(), err)
+		}
+		p := netProtocolStats{
+			protocol: fields[idx["protocol"]],
+			module:   fields[idx["module"]],
+			sockets:  sockets,
+			pressure: -1,
+		}
+		switch fields[idx["press"]] {
+		case "yes":
+			p.pressure = 1
+		case "no":
+			p.pressure = 0
+		}
+		protocols = append(protocols, p)
+	}
+	return protocols, scanner.Err()
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonet_protocols
+// +build !nonet_protocols
+
+package collector
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseNetProtocols(t *testing.T) {
+	file, err := os.Open("fixtures/proc/net/protocols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	protocols, err := parseNetProtocols(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(protocols) != 6 {
+		t.Fatalf("want 6 protocols, got %d", len(protocols))
+	}
+	for i, want := range map[int]netProtocolStats{
+		0: {protocol: "PACKET", module: "kernel", sockets: 2, pressure: -1},
+		4: {protocol: "TCP", module: "kernel", sockets: 12, pressure: 1},
+		5: {protocol: "SCTP", module: "sctp", sockets: 1, pressure: 0},
+	} {
+		if protocols[i] != want {
+			t.Errorf("want %+v, got %+v", want, protocols[i])
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetlink
+// +build !nonetlink
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const netlinkSubsystem = "netlink"
+
+// netlinkProtocols maps the netlink protocol numbers (see linux/netlink.h)
+// to names.
+var netlinkProtocols = map[string]string{
+	"0":  "route",
+	"1":  "unused",
+	"2":  "usersock",
+	"3":  "firewall",
+	"4":  "sock_diag",
+	"5":  "nflog",
+	"6":  "xfrm",
+	"7":  "selinux",
+	"8":  "iscsi",
+	"9":  "audit",
+	"10": "fib_lookup",
+	"11": "connector",
+	"12": "netfilter",
+	"13": "ip6_fw",
+	"14": "dnrtmsg",
+	"15": "kobject_uevent",
+	"16": "generic",
+	"18": "scsitransport",
+	"19": "ecryptfs",
+	"20": "rdma",
+	"21": "crypto",
+	"22": "smc",
+}
+
+type netlinkStats struct {
+	sockets uint64
+	rmem    uint64
+	drops   uint64
+}
+
+type netlinkCollector struct {
+	drops, rmem, sockets typedDesc
+	logger               log.Logger
+}
+
+func init() {
+	registerCollector("netlink", defaultDisabled, NewNetlinkCollector)
+}
+
+// NewNetlinkCollector returns a new Collector exposing netlink socket stats.
+func NewNetlinkCollector(logger log.Logger) (Collector, error) {
+	labels := []string{"protocol"}
+	return &netlinkCollector{
+		drops: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, netlinkSubsystem, "drops_total"),
+			"Number of messages dropped by netlink sockets, because the receiver was too slow.",
+			labels, nil,
+		), prometheus.CounterValue},
+		rmem: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, netlinkSubsystem, "rmem_bytes"),
+			"Memory allocated for the receive queues of netlink sockets.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		sockets: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, netlinkSubsystem, "socket_count"),
+			"Number of open netlink sockets.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes /proc/net/netlink stats per
+// protocol.
+func (c *netlinkCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/netlink"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseNetlinkStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for protocol, s := range stats {
+		ch <- c.drops.mustNewConstMetric(float64(s.drops), protocol)
+		ch <- c.rmem.mustNewConstMetric(float64(s.rmem), protocol)
+		ch <- c.sockets.mustNewConstMetric(float64(s.sockets), protocol)
+	}
+	return nil
+}
+
+func parseNetlinkStats(r io.Reader) (map[string]*netlinkStats, error) {
+	var (
+		stats   = map[string]*netlinkStats{}
+		scanner = bufio.NewScanner(r)
+	)
+
+	// sk Eth Pid Groups Rmem Wmem Dump Locks Drops Inode
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			return nil, fmt.Errorf("invalid line: %q", scanner.Text())
+		}
+		protocol, ok := netlinkProtocols[fields[1]]
+		if !ok {
+			protocol = fields[1]
+		}
+		rmem, err := strconv.ParseUint(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Rmem: %w", err)
+		}
+		drops, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Drops: %w", err)
+		}
+		s, ok := stats[protocol]
+		if !ok {
+			s = &netlinkStats{}
+			stats[protocol] = s
+		}
+		s.sockets++
+		s.rmem += rmem
+		s.drops += drops
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonetlink
+// +build !nonetlink
+
+package collector
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNetlinkStats(t *testing.T) {
+	file, err := os.Open("fixtures/proc/net/netlink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stats, err := parseNetlinkStats(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*netlinkStats{
+		"route":          {sockets: 2, rmem: 2304, drops: 17},
+		"sock_diag":      {sockets: 1},
+		"audit":          {sockets: 1},
+		"kobject_uevent": {sockets: 1},
+		"generic":        {sockets: 1, rmem: 1024, drops: 3},
+		"42":             {sockets: 1},
+	}
+	if !reflect.DeepEqual(want, stats) {
+		for protocol, s := range stats {
+			t.Logf("%s: %+v", protocol, *s)
+		}
+		t.Fatal("unexpected netlink stats")
+	}
+}
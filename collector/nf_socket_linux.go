@@ -0,0 +1,109 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonf_socket
+// +build !nonf_socket
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type nfSocketCollector struct {
+	entries, buckets typedDesc
+	logger           log.Logger
+}
+
+func init() {
+	registerCollector("nf_socket", defaultDisabled, NewNfSocketCollector)
+}
+
+// NewNfSocketCollector returns a new Collector exposing netfilter socket
+// table stats.
+func NewNfSocketCollector(logger log.Logger) (Collector, error) {
+	return &nfSocketCollector{
+		entries: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nf_socket", "entries"),
+			"Number of entries in the netfilter socket table.",
+			[]string{"family"}, nil,
+		), prometheus.GaugeValue},
+		buckets: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nf_conntrack", "buckets"),
+			"Size of the conntrack hash table.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *nfSocketCollector) Update(ch chan<- prometheus.Metric) error {
+	found := false
+	for _, t := range []struct {
+		family, file string
+	}{
+		{"ipv4", "net/nf_socket_4"},
+		{"ipv6", "net/nf_socket_6"},
+	} {
+		n, err := countNfSocketEntries(procFilePath(t.file))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		ch <- c.entries.mustNewConstMetric(float64(n), t.family)
+		found = true
+	}
+
+	buckets, err := readUintFromFile(procFilePath("sys/net/netfilter/nf_conntrack_buckets"))
+	if err == nil {
+		ch <- c.buckets.mustNewConstMetric(float64(buckets))
+		found = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if !found {
+		level.Debug(c.logger).Log("msg", "nf_socket and nf_conntrack modules not loaded")
+		return ErrNoData
+	}
+	return nil
+}
+
+// countNfSocketEntries returns the number of non-empty lines of the given
+// file.
+func countNfSocketEntries(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			n++
+		}
+	}
+	return n, scanner.Err()
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonfsd_clients
+// +build !nonfsd_clients
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// nfsdClientStateTypes are the state types listed in the states file of a
+// NFSv4 client.
+var nfsdClientStateTypes = []string{"open", "lock", "deleg", "layout"}
+
+type nfsdClientsCollector struct {
+	clients, states typedDesc
+	logger          log.Logger
+}
+
+func init() {
+	registerCollector("nfsd_clients", defaultDisabled, NewNfsdClientsCollector)
+}
+
+// NewNfsdClientsCollector returns a new Collector exposing the NFSv4 clients
+// of the NFS server and the number of their open files, locks, delegations
+// and layouts from /proc/fs/nfsd/clients/ (Linux 5.3+).
+func NewNfsdClientsCollector(logger log.Logger) (Collector, error) {
+	return &nfsdClientsCollector{
+		clients: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nfsd", "clients"),
+			"Number of NFSv4 clients known to the server by client IP address.",
+			[]string{"address"}, nil,
+		), prometheus.GaugeValue},
+		states: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nfsd", "client_states"),
+			"Number of open files (open), locks (lock), delegations (deleg) and pNFS layouts (layout) held by the NFSv4 clients by client IP address.",
+			[]string{"address", "type"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Clients and states get summed up per IP
+// address, since the port changes on reconnect. The states files are
+// readable by root only, so states get exposed only if readable.
+func (c *nfsdClientsCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(procFilePath("fs/nfsd/clients/*"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		if _, err := os.Stat(procFilePath("fs/nfsd/clients")); err != nil {
+			level.Debug(c.logger).Log("msg", "No nfsd clients dir found (nfsd not running or Linux < 5.3)", "err", err)
+			return ErrNoData
+		}
+	}
+
+	clients := map[string]uint64{}
+	states := map[string]map[string]uint64{}
+	for _, dir := range dirs {
+		address, err := readNfsdClientAddress(filepath.Join(dir, "info"))
+		if err != nil {
+			// the client may have gone in the meantime
+			level.Debug(c.logger).Log("msg", "Failed to read nfsd client info", "client", filepath.Base(dir), "err", err)
+			continue
+		}
+		clients[address]++
+		file, err := os.Open(filepath.Join(dir, "states"))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				level.Debug(c.logger).Log("msg", "Failed to open nfsd client states", "client", filepath.Base(dir), "err", err)
+			}
+			continue
+		}
+		if states[address] == nil {
+			states[address] = map[string]uint64{}
+		}
+		err = countNfsdClientStates(file, states[address])
+		file.Close()
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read nfsd client states", "client", filepath.Base(dir), "err", err)
+		}
+	}
+
+	for address, n := range clients {
+		ch <- c.clients.mustNewConstMetric(float64(n), address)
+		if states[address] == nil {
+			continue
+		}
+		for _, typ := range nfsdClientStateTypes {
+			ch <- c.states.mustNewConstMetric(float64(states[address][typ]), address, typ)
+		}
+	}
+	return nil
+}
+
+// readNfsdClientAddress returns the IP address of the 'address: "<ip>:<port>"'
+// line of the given client info file.
+func readNfsdClientAddress(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "address:") {
+			continue
+		}
+		v := strings.Trim(strings.TrimSpace(line[len("address:"):]), `"`)
+		if host, _, err := net.SplitHostPort(v); err == nil {
+			return host, nil
+		}
+		return v, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("no address line found")
+}
+
+// countNfsdClientStates adds the number of states by type of the given
+// states content, i.e. lines like
+// "- 0x...: { type: open, access: rw, deny: --, ... }", to counts.
+func countNfsdClientStates(r io.Reader, counts map[string]uint64) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, "type: ")
+		if i < 0 {
+			continue
+		}
+		typ := line[i+len("type: "):]
+		if j := strings.IndexAny(typ, ", }"); j >= 0 {
+			typ = typ[:j]
+		}
+		counts[typ]++
+	}
+	return scanner.Err()
+}
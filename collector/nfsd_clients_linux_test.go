@@ -0,0 +1,127 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonfsd_clients
+// +build !nonfsd_clients
+
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadNfsdClientAddress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfsd_clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tc := range []struct {
+		name, info, want string
+		err              bool
+	}{
+		{
+			name: "ipv4",
+			info: "clientid: 0x6d0ad7ec5f2a8b45\naddress: \"192.168.1.10:856\"\nstatus: confirmed\nname: \"Linux NFSv4.2 host1\"\nminor version: 2\n",
+			want: "192.168.1.10",
+		},
+		{
+			name: "ipv6",
+			info: "clientid: 0x6d0ad7ec5f2a8b46\naddress: \"[fd00::1]:740\"\nstatus: confirmed\n",
+			want: "fd00::1",
+		},
+		{
+			name: "no port",
+			info: "address: \"192.168.1.11\"\n",
+			want: "192.168.1.11",
+		},
+		{
+			name: "no address",
+			info: "clientid: 0x6d0ad7ec5f2a8b47\nstatus: unconfirmed\n",
+			err:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			if err := ioutil.WriteFile(path, []byte(tc.info), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readNfsdClientAddress(path)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := readNfsdClientAddress(filepath.Join(dir, "gone")); !os.IsNotExist(err) {
+		t.Errorf("want not exist error, got %v", err)
+	}
+}
+
+func TestCountNfsdClientStates(t *testing.T) {
+	for _, tc := range []struct {
+		name, states string
+		want         map[string]uint64
+	}{
+		{
+			name:   "empty",
+			states: "",
+			want:   map[string]uint64{},
+		},
+		{
+			name: "all types",
+			states: `- 0x00000001bd5d0c64a1b2c3d4: { type: open, access: rw, deny: --, superblock: "fd:10:13649", filename: "/export/a", owner: "open id:\x00\x00\x00\x2a" }
+- 0x00000002bd5d0c64a1b2c3d4: { type: open, access: r, deny: --, superblock: "fd:10:13650", filename: "/export/b", owner: "open id:\x00\x00\x00\x2b" }
+- 0x00000003bd5d0c64a1b2c3d4: { type: lock, superblock: "fd:10:13649", filename: "/export/a", owner: "lock id:\x00\x00\x00\x2a" }
+- 0x00000004bd5d0c64a1b2c3d4: { type: deleg, access: r, superblock: "fd:10:13650", filename: "/export/b" }
+- 0x00000005bd5d0c64a1b2c3d4: { type: layout, superblock: "fd:10:13649", filename: "/export/a" }
+`,
+			want: map[string]uint64{"open": 2, "lock": 1, "deleg": 1, "layout": 1},
+		},
+		{
+			name:   "type last",
+			states: "- 0x00000001bd5d0c64a1b2c3d4: { superblock: \"fd:10:13649\", type: deleg }\n",
+			want:   map[string]uint64{"deleg": 1},
+		},
+		{
+			name:   "other lines",
+			states: "# comment\n\n- 0x00000001bd5d0c64a1b2c3d4: { type: open, access: rw }\n",
+			want:   map[string]uint64{"open": 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := map[string]uint64{}
+			if err := countNfsdClientStates(strings.NewReader(tc.states), got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
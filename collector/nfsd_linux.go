@@ -22,8 +22,10 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -36,7 +38,8 @@ import (
 )
 
 var (
-	skipProto = kingpin.Flag("collector.nfsd.skip", "Skip stats for the given comma separated list of NFS versions or stats group, i.e. 2, 3, 4, 4ops, or threads.").Default("").String()
+	nfsdSkipTokenRE = regexp.MustCompile(`^[0-9a-z]+$`)
+	skipProto       = kingpin.Flag("collector.nfsd.skip", "Skip stats for the given comma separated list of NFS versions or stats group, i.e. 2, 3, 4, 4ops, threads, or exports.").Default("").String()
 )
 
 // A nfsdCollector is a Collector which gathers metrics from /proc/net/rpc/nfsd.
@@ -54,11 +57,15 @@ type nfsdCollector struct {
 	nfsV4callDesc    *prometheus.Desc
 	nfsV4opDesc      *prometheus.Desc
 	nfsdPoolOpDesc   *prometheus.Desc
+	exportReadDesc   *prometheus.Desc
+	exportWriteDesc  *prometheus.Desc
+	exportStaleDesc  *prometheus.Desc
 	skipV2           bool
 	skipV3           bool
 	skipV4           bool
 	skipV4ops        bool
 	skipThreads      bool
+	skipExports      bool
 	logger           log.Logger
 }
 
@@ -77,22 +84,29 @@ func NewNFSdCollector(logger log.Logger) (Collector, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
-	skipV2, skipV3, skipV4, skipV4ops, skipThreads := false, false, false, false, false
-	v := strings.Split(*skipProto,",")
-	for _, s := range v {
-		s = strings.TrimSpace(s)
-		if s == "2" {
-			skipV2 = true;
-		} else if s == "3" {
-			skipV3 = true;
-		} else if s == "4" {
-			skipV4 = true;
-		} else if s == "4ops" {
-			skipV4ops = true;
-		} else if s == "threads" {
-			skipThreads = true;
-		} else {
-			level.Warn(logger).Log("msg", "Unknown NFS version", s , "ignored.")
+	skipV2, skipV3, skipV4, skipV4ops, skipThreads, skipExports := false, false, false, false, false, false
+	for _, s := range strings.Split(*skipProto, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case "":
+		case "2":
+			skipV2 = true
+		case "3":
+			skipV3 = true
+		case "4":
+			skipV4 = true
+		case "4ops":
+			skipV4ops = true
+		case "threads":
+			skipThreads = true
+		case "exports":
+			skipExports = true
+		default:
+			// Typos like "4op" or "thread" would silently not skip anything.
+			if nfsdSkipTokenRE.MatchString(s) {
+				return nil, fmt.Errorf("invalid --collector.nfsd.skip token %q, valid are 2, 3, 4, 4ops, threads and exports", s)
+			}
+			level.Warn(logger).Log("msg", "Unknown NFS skip token", "token", s)
 		}
 	}
 
@@ -158,11 +172,27 @@ func NewNFSdCollector(logger log.Logger) (Collector, error) {
 			"Thread pool stats counter. See /proc/fs/nfsd/pool_stats.",
 			[]string{"pool", "name"}, nil,
 		),
+		exportReadDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsdSubsystem, "export_read_bytes_total"),
+			"Total number of bytes returned to read requests of the export to the client. See /proc/fs/nfsd/export_stats.",
+			[]string{"path", "client"}, nil,
+		),
+		exportWriteDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsdSubsystem, "export_write_bytes_total"),
+			"Total number of bytes passed in write requests of the export to the client. See /proc/fs/nfsd/export_stats.",
+			[]string{"path", "client"}, nil,
+		),
+		exportStaleDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsdSubsystem, "export_stale_file_handles_total"),
+			"Total number of stale file handle errors of the export to the client. See /proc/fs/nfsd/export_stats.",
+			[]string{"path", "client"}, nil,
+		),
 		skipV2: skipV2,
 		skipV3: skipV3,
 		skipV4: skipV4,
 		skipV4ops: skipV4ops,
 		skipThreads: skipThreads,
+		skipExports: skipExports,
 		logger: logger,
 	}, nil
 }
@@ -181,6 +211,7 @@ func (c *nfsdCollector) Update(ch chan<- prometheus.Metric) error {
 	c.updateNFSdReplyCacheStats(ch, &stats.ReplyCache)
 	c.updateNFSdFileHandlesStats(ch, &stats.FileHandles)
 	c.updateNFSdInputOutputStats(ch, &stats.InputOutput)
+	c.updateNFSdExportIO(ch)
 	c.updateNFSdThreadsStats(ch, &stats.Threads)
 	c.updateNFSdNetworkStats(ch, &stats.Network)
 	c.updateNFSdServerRPCStats(ch, &stats.RpcServer)
@@ -211,6 +242,33 @@ func (c *nfsdCollector) updateNFSdInputOutputStats(ch chan<- prometheus.Metric,
 	ch <- prometheus.MustNewConstMetric(c.ioDesc, prometheus.CounterValue, float64(s.Write), "write")
 }
 
+// updateNFSdExportIO collects the per export and client I/O stats of
+// /proc/fs/nfsd/export_stats (Linux 6.3+). Without it only the aggregate
+// node_nfsd_io_bytes are available.
+func (c *nfsdCollector) updateNFSdExportIO(ch chan<- prometheus.Metric) {
+	if c.skipExports {
+		return
+	}
+
+	file, err := os.Open(procFilePath("fs/nfsd/export_stats"))
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "No per export stats, falling back to node_nfsd_io_bytes", "err", err)
+		return
+	}
+	defer file.Close()
+
+	exports, err := parseNfsdExportStats(file)
+	if err != nil {
+		level.Warn(c.logger).Log("msg", "Failed to parse export stats", "err", err)
+		return
+	}
+	for _, e := range exports {
+		ch <- prometheus.MustNewConstMetric(c.exportReadDesc, prometheus.CounterValue, float64(e.read), e.path, e.client)
+		ch <- prometheus.MustNewConstMetric(c.exportWriteDesc, prometheus.CounterValue, float64(e.write), e.path, e.client)
+		ch <- prometheus.MustNewConstMetric(c.exportStaleDesc, prometheus.CounterValue, float64(e.staleHandles), e.path, e.client)
+	}
+}
+
 // updateNFSdThreadsStats collects statistics for kernel server threads.
 func (c *nfsdCollector) updateNFSdThreadsStats(ch chan<- prometheus.Metric, s *nfs.Threads) {
 	ch <- prometheus.MustNewConstMetric(c.thDesc, prometheus.GaugeValue, float64(s.Threads))
@@ -240,7 +298,7 @@ func (c *nfsdCollector) updateNFSdRequestsV2Stats(ch chan<- prometheus.Metric, s
 		return
 	}
 	v := reflect.ValueOf(s).Elem()
-	for i := int(s.Fields); i > 0; i-- {
+	for i := 1; i <= int(s.Fields); i++ {
 		field := v.Field(i)
 		ch <- prometheus.MustNewConstMetric(c.nfsV2callDesc, prometheus.CounterValue, float64(field.Uint()), v.Type().Field(i).Name)
 	}
@@ -252,7 +310,7 @@ func (c *nfsdCollector) updateNFSdRequestsV3Stats(ch chan<- prometheus.Metric, s
 		return
 	}
 	v := reflect.ValueOf(s).Elem()
-	for i := int(s.Fields); i > 0; i-- {
+	for i := 1; i <= int(s.Fields); i++ {
 		field := v.Field(i)
 		ch <- prometheus.MustNewConstMetric(c.nfsV3callDesc, prometheus.CounterValue, float64(field.Uint()), v.Type().Field(i).Name)
 	}
@@ -264,7 +322,7 @@ func (c *nfsdCollector) updateNFSdRequestsV4Stats(ch chan<- prometheus.Metric, s
 		return
 	}
 	v := reflect.ValueOf(s).Elem()
-	for i := int(s.Fields); i > 0; i-- {
+	for i := 1; i <= int(s.Fields); i++ {
 		field := v.Field(i)
 		ch <- prometheus.MustNewConstMetric(c.nfsV4callDesc, prometheus.CounterValue, float64(field.Uint()), v.Type().Field(i).Name)
 	}
@@ -276,7 +334,7 @@ func (c *nfsdCollector) updateNFSdRequestsV4Ops(ch chan<- prometheus.Metric, s *
 		return
 	}
 	v := reflect.ValueOf(s).Elem()
-	for i := int(s.Fields); i > 2; i-- {
+	for i := 3; i <= int(s.Fields); i++ {
 		field := v.Field(i)
 		ch <- prometheus.MustNewConstMetric(c.nfsV4opDesc, prometheus.CounterValue, float64(field.Uint()), v.Type().Field(i).Name)
 	}
@@ -315,3 +373,56 @@ func (c *nfsdCollector) updateNFSdThreadStats(ch chan<- prometheus.Metric) {
 		}
 	}
 }
+
+// nfsdExportStats are the counters of an export to a client (auth domain).
+type nfsdExportStats struct {
+	path, client              string
+	read, write, staleHandles uint64
+}
+
+// parseNfsdExportStats parses the given export_stats content. Each export
+// starts with a "<path>\t<client>\t<start-time>" line followed by tab
+// indented "<name>: <value>" lines.
+func parseNfsdExportStats(r io.Reader) ([]nfsdExportStats, error) {
+	var exports []nfsdExportStats
+	var cur *nfsdExportStats
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] != '\t' {
+			fields := strings.Split(line, "\t")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("invalid export line %q", line)
+			}
+			exports = append(exports, nfsdExportStats{
+				path:   unescapeOctal(fields[0]),
+				client: unescapeOctal(fields[1]),
+			})
+			cur = &exports[len(exports)-1]
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("stats line %q without export", line)
+		}
+		kv := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid stats line %q", line)
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(kv[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in line %q: %w", line, err)
+		}
+		switch kv[0] {
+		case "io_read":
+			cur.read = value
+		case "io_write":
+			cur.write = value
+		case "fh_stale":
+			cur.staleHandles = value
+		}
+	}
+	return exports, scanner.Err()
+}
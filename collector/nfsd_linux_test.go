@@ -0,0 +1,111 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonfsd
+// +build !nonfsd
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/procfs/nfs"
+)
+
+func TestNFSdRequestsOrder(t *testing.T) {
+	desc := prometheus.NewDesc("node_nfsd_test_calls", "test", []string{"name"}, nil)
+	c := &nfsdCollector{nfsV3callDesc: desc}
+	s := &nfs.V3stats{Fields: 4, Null: 1, GetAttr: 2, SetAttr: 3, Lookup: 4}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.updateNFSdRequestsV3Stats(ch, s)
+		close(ch)
+	}()
+
+	var names []string
+	var values []float64
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, pb.GetLabel()[0].GetValue())
+		values = append(values, pb.GetCounter().GetValue())
+	}
+	if want := []string{"Null", "GetAttr", "SetAttr", "Lookup"}; !reflect.DeepEqual(want, names) {
+		t.Errorf("want %v, got %v", want, names)
+	}
+	if want := []float64{1, 2, 3, 4}; !reflect.DeepEqual(want, values) {
+		t.Errorf("want %v, got %v", want, values)
+	}
+}
+
+func TestNFSdSkipTokens(t *testing.T) {
+	*procPath = "fixtures/proc"
+	defer func() { *skipProto = "" }()
+	for _, tc := range []struct {
+		skip  string
+		fails bool
+	}{
+		{skip: ""},
+		{skip: "2,3"},
+		{skip: " 4 , 4OPS,threads,exports"},
+		{skip: "2,,3"},
+		// not a plain token, so probably not meant as one, gets logged only
+		{skip: "v4.1"},
+		{skip: "4op", fails: true},
+		{skip: "3,thread", fails: true},
+		{skip: "5", fails: true},
+	} {
+		*skipProto = tc.skip
+		_, err := NewNFSdCollector(log.NewNopLogger())
+		if tc.fails && err == nil {
+			t.Errorf("%q: want error", tc.skip)
+		} else if !tc.fails && err != nil {
+			t.Errorf("%q: %v", tc.skip, err)
+		}
+	}
+}
+
+func TestParseNfsdExportStats(t *testing.T) {
+	exports, err := parseNfsdExportStats(strings.NewReader(`# Version 1.1
+# Path Client Start-time
+#	Stats
+/export/home	*.example.com	92
+	fh_stale: 1
+	io_read: 4096
+	io_write: 512
+
+/export/my\040data	192.168.1.0/24	117
+	fh_stale: 0
+	io_read: 9
+	io_write: 0
+
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []nfsdExportStats{
+		{path: "/export/home", client: "*.example.com", read: 4096, write: 512, staleHandles: 1},
+		{path: "/export/my data", client: "192.168.1.0/24", read: 9},
+	}
+	if !reflect.DeepEqual(want, exports) {
+		t.Errorf("want %+v, got %+v", want, exports)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonuma_maps
+// +build !nonuma_maps
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var numaMapsPID = kingpin.Flag("collector.numa_maps.pid", "PID of the process whose NUMA memory placement should be reported, 'self' means node_exporter itself.").Default("self").String()
+
+// numaMapsKey identifies the pages of a memory policy on a NUMA node.
+type numaMapsKey struct {
+	policy, node string
+}
+
+type numaMapsStats struct {
+	anon, file map[numaMapsKey]uint64
+}
+
+type numaMapsCollector struct {
+	anon, file typedDesc
+	pid        string
+	logger     log.Logger
+}
+
+func init() {
+	registerCollector("numa_maps", defaultDisabled, NewNumaMapsCollector)
+}
+
+// NewNumaMapsCollector returns a new Collector exposing the NUMA placement
+// of the memory of a process.
+func NewNumaMapsCollector(logger log.Logger) (Collector, error) {
+	pid := *numaMapsPID
+	if pid == "self" {
+		pid = readProcSelf()
+	} else if _, err := strconv.ParseUint(pid, 10, 32); err != nil {
+		return nil, fmt.Errorf("invalid --collector.numa_maps.pid %q", pid)
+	}
+	labels := []string{"pid", "policy", "node"}
+	return &numaMapsCollector{
+		anon: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "numa_maps", "anon_pages"),
+			"Number of anonymous pages of the process on the NUMA node by memory policy.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		file: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "numa_maps", "file_pages"),
+			"Number of file backed pages of the process on the NUMA node by memory policy.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		pid:    pid,
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *numaMapsCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath(c.pid + "/numa_maps"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseNumaMaps(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for k, v := range stats.anon {
+		ch <- c.anon.mustNewConstMetric(float64(v), c.pid, k.policy, k.node)
+	}
+	for k, v := range stats.file {
+		ch <- c.file.mustNewConstMetric(float64(v), c.pid, k.policy, k.node)
+	}
+	return nil
+}
+
+// parseNumaMaps sums up the per node page counts (N<node>=<pages>) of the
+// anonymous and file backed mappings of the given numa_maps content. Policy
+// arguments like the node list of "bind:0-1" get dropped.
+func parseNumaMaps(r io.Reader) (numaMapsStats, error) {
+	stats := numaMapsStats{
+		anon: map[numaMapsKey]uint64{},
+		file: map[numaMapsKey]uint64{},
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		policy := strings.SplitN(fields[1], ":", 2)[0]
+		var target map[numaMapsKey]uint64
+		nodes := map[string]uint64{}
+		for _, field := range fields[2:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch {
+			case kv[0] == "file":
+				target = stats.file
+			case kv[0] == "anon" && target == nil:
+				target = stats.anon
+			case len(kv[0]) > 1 && kv[0][0] == 'N':
+				if _, err := strconv.Atoi(kv[0][1:]); err != nil {
+					continue
+				}
+				pages, err := strconv.ParseUint(kv[1], 10, 64)
+				if err != nil {
+					return stats, fmt.Errorf("invalid page count in line %q: %w", scanner.Text(), err)
+				}
+				nodes[kv[0][1:]] = pages
+			}
+		}
+		if target == nil {
+			continue
+		}
+		for node, pages := range nodes {
+			target[numaMapsKey{policy, node}] += pages
+		}
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nonvram
+// +build !nonvram
+
+package collector
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const nvramSubsystem = "nvram"
+
+var nvramVariablesPath = kingpin.Flag("collector.nvram.variables-path", "Mount point of the efivarfs. Default: <path.sysfs>/firmware/efi/efivars").String()
+
+type nvramCollector struct {
+	variables, size, runtimeMap typedDesc
+	logger                      log.Logger
+}
+
+func init() {
+	registerCollector("nvram", defaultDisabled, NewNvramCollector)
+}
+
+// NewNvramCollector returns a new Collector exposing EFI variable stats.
+func NewNvramCollector(logger log.Logger) (Collector, error) {
+	return &nvramCollector{
+		variables: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nvramSubsystem, "variable_count"),
+			"Number of EFI variables.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		size: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nvramSubsystem, "variable_size_bytes"),
+			"Total size of all EFI variables incl. their attributes.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		runtimeMap: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nvramSubsystem, "runtime_map_entries"),
+			"Number of EFI runtime memory map entries.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the number and size of EFI
+// variables.
+func (c *nvramCollector) Update(ch chan<- prometheus.Metric) error {
+	if _, err := os.Stat(sysFilePath("firmware/efi")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Not an EFI system")
+			return ErrNoData
+		}
+		return err
+	}
+
+	path := *nvramVariablesPath
+	if path == "" {
+		path = sysFilePath("firmware/efi/efivars")
+	}
+	vars, err := ioutil.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "efivarfs not mounted", "path", path)
+			return ErrNoData
+		}
+		return err
+	}
+	var size int64
+	for _, v := range vars {
+		size += v.Size()
+	}
+	ch <- c.variables.mustNewConstMetric(float64(len(vars)))
+	ch <- c.size.mustNewConstMetric(float64(size))
+
+	// available if the kernel has CONFIG_EFI_RUNTIME_MAP set, only
+	entries, err := ioutil.ReadDir(sysFilePath("firmware/efi/runtime-map"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	ch <- c.runtimeMap.mustNewConstMetric(float64(len(entries)))
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nopagecache
+// +build !nopagecache
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	pageCacheFiles      = kingpin.Flag("collector.pagecache.files", "Comma separated list of files or directories (walked recursively) to check for pages resident in the page cache.").Default("").String()
+	pageCacheSampleRate = kingpin.Flag("collector.pagecache.sample-rate", "Check every Nth page only.").Default("1").Int()
+)
+
+// pageCacheWindowPages is the max. number of pages mapped at once.
+const pageCacheWindowPages = 4096
+
+type pageCacheCollector struct {
+	ratio, bytes typedDesc
+	paths        []string
+	sampleRate   int
+	pageSize     int
+	logger       log.Logger
+}
+
+func init() {
+	registerCollector("pagecache", defaultDisabled, NewPageCacheCollector)
+}
+
+// NewPageCacheCollector returns a new Collector exposing how much of the
+// configured files is resident in the page cache.
+func NewPageCacheCollector(logger log.Logger) (Collector, error) {
+	c := &pageCacheCollector{
+		ratio: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pagecache", "file_resident_ratio"),
+			"Fraction of the (sampled) pages of the path resident in the page cache.",
+			[]string{"path"}, nil,
+		), prometheus.GaugeValue},
+		bytes: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pagecache", "file_resident_bytes"),
+			"Estimated number of bytes of the path resident in the page cache.",
+			[]string{"path"}, nil,
+		), prometheus.GaugeValue},
+		sampleRate: *pageCacheSampleRate,
+		pageSize:   os.Getpagesize(),
+		logger:     logger,
+	}
+	if c.sampleRate < 1 {
+		c.sampleRate = 1
+	}
+	for _, path := range strings.Split(*pageCacheFiles, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			c.paths = append(c.paths, path)
+		}
+	}
+	return c, nil
+}
+
+// Update implements Collector. The configured paths are relative to
+// --path.rootfs, but get reported as given.
+func (c *pageCacheCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.paths) == 0 {
+		level.Debug(c.logger).Log("msg", "No files configured via --collector.pagecache.files")
+		return ErrNoData
+	}
+	for _, path := range c.paths {
+		var sampled, resident, size int64
+		err := filepath.Walk(rootfsFilePath(path), func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+					level.Debug(c.logger).Log("msg", "Skipping file", "file", file, "err", err)
+					return nil
+				}
+				return err
+			}
+			if !info.Mode().IsRegular() || info.Size() == 0 {
+				return nil
+			}
+			s, r, err := c.mincore(file, info.Size())
+			if err != nil {
+				level.Debug(c.logger).Log("msg", "Failed to check page cache residency", "file", file, "err", err)
+				return nil
+			}
+			sampled += s
+			resident += r
+			size += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if sampled == 0 {
+			continue
+		}
+		ratio := float64(resident) / float64(sampled)
+		ch <- c.ratio.mustNewConstMetric(ratio, path)
+		ch <- c.bytes.mustNewConstMetric(ratio*float64(size), path)
+	}
+	return nil
+}
+
+// mincore returns the number of sampled pages of the given file and how
+// many of them are resident in the page cache. The file gets mapped in
+// windows of at most pageCacheWindowPages pages. When sampling, each window
+// is the first page of a stride of sampleRate pages, so the skipped pages
+// never get mapped.
+func (c *pageCacheCollector) mincore(file string, size int64) (int64, int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	pageSize := int64(c.pageSize)
+	window, stride := int64(pageCacheWindowPages), int64(pageCacheWindowPages)
+	if c.sampleRate > 1 {
+		window, stride = 1, int64(c.sampleRate)
+	}
+	pages := (size + pageSize - 1) / pageSize
+	vec := make([]byte, window)
+
+	var sampled, resident int64
+	for off := int64(0); off < pages; off += stride {
+		n := window
+		if pages-off < n {
+			n = pages - off
+		}
+		length := n * pageSize
+		if size-off*pageSize < length {
+			length = size - off*pageSize
+		}
+		data, err := unix.Mmap(int(f.Fd()), off*pageSize, int(length), unix.PROT_READ, unix.MAP_SHARED)
+		if err != nil {
+			return 0, 0, err
+		}
+		_, _, errno := unix.Syscall(unix.SYS_MINCORE, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), uintptr(unsafe.Pointer(&vec[0])))
+		unix.Munmap(data)
+		if errno != 0 {
+			return 0, 0, errno
+		}
+		for _, v := range vec[:n] {
+			sampled++
+			// the least significant bit tells, whether the page is resident
+			if v&1 == 1 {
+				resident++
+			}
+		}
+	}
+	return sampled, resident, nil
+}
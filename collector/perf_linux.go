@@ -36,7 +36,7 @@ const (
 
 var (
 	perfCPUsFlag       = kingpin.Flag("collector.perf.cpus", "List of CPUs from which perf metrics should be collected").Default("").String()
-	perfTracepointFlag = kingpin.Flag("collector.perf.tracepoint", "perf tracepoint that should be collected").Strings()
+	perfTracepointFlag = cumulativeFlag(kingpin.Flag("collector.perf.tracepoint", "perf tracepoint that should be collected").Strings())
 )
 
 func init() {
@@ -563,6 +563,31 @@ func NewPerfCollector(logger log.Logger) (Collector, error) {
 	return collector, nil
 }
 
+// Close implements closer and closes all profilers.
+func (c *perfCollector) Close() error {
+	var errs []error
+	for _, p := range c.perfHwProfilers {
+		errs = append(errs, (*p).Close())
+	}
+	for _, p := range c.perfSwProfilers {
+		errs = append(errs, (*p).Close())
+	}
+	for _, p := range c.perfCacheProfilers {
+		errs = append(errs, (*p).Close())
+	}
+	if c.tracepointCollector != nil {
+		for _, p := range c.tracepointCollector.profilers {
+			errs = append(errs, p.Close())
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Update implements the Collector interface and will collect metrics per CPU.
 func (c *perfCollector) Update(ch chan<- prometheus.Metric) error {
 	if err := c.updateHardwareStats(ch); err != nil {
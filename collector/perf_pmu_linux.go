@@ -0,0 +1,166 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// pmuCounter is a perf event counter opened on one CPU of a socket.
+type pmuCounter struct {
+	fd     int
+	socket string
+}
+
+// openUncorePMUCounters opens the event with the given config of the given
+// uncore PMU (a /sys/bus/event_source/devices/ entry) on one CPU of each
+// socket listed in the PMU's cpumask.
+func openUncorePMUCounters(pmu string, config uint64) ([]pmuCounter, error) {
+	pmuType, err := readUintFromFile(filepath.Join(pmu, "type"))
+	if err != nil {
+		return nil, err
+	}
+	mask, err := readStringFromFile(filepath.Join(pmu, "cpumask"))
+	if err != nil {
+		return nil, err
+	}
+	cpus, err := parseCPUList(mask)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(cpus))
+	for cpu := range cpus {
+		ids = append(ids, cpu)
+	}
+	sort.Ints(ids)
+
+	var counters []pmuCounter
+	for _, cpu := range ids {
+		socket, err := readStringFromFile(sysFilePath(fmt.Sprintf("devices/system/cpu/cpu%d/topology/physical_package_id", cpu)))
+		if err != nil {
+			closePMUCounters(counters)
+			return nil, err
+		}
+		attr := unix.PerfEventAttr{
+			Type:   uint32(pmuType),
+			Config: config,
+		}
+		attr.Size = uint32(unsafe.Sizeof(attr))
+		fd, err := unix.PerfEventOpen(&attr, -1, cpu, -1, unix.PERF_FLAG_FD_CLOEXEC)
+		if err != nil {
+			closePMUCounters(counters)
+			return nil, err
+		}
+		counters = append(counters, pmuCounter{fd, socket})
+	}
+	return counters, nil
+}
+
+func closePMUCounters(counters []pmuCounter) {
+	for _, counter := range counters {
+		counter.close()
+	}
+}
+
+func (c pmuCounter) close() {
+	unix.Close(c.fd)
+}
+
+// readPMUCounter returns the current value of the given counter.
+func readPMUCounter(counter pmuCounter) (uint64, error) {
+	// the kernel writes the counter in host byte order
+	var value uint64
+	if _, err := unix.Read(counter.fd, (*[8]byte)(unsafe.Pointer(&value))[:]); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// parsePMUEventConfig translates the given event of the PMU's events
+// directory into the perf config value.
+func parsePMUEventConfig(pmu, event string) (uint64, error) {
+	desc, err := readStringFromFile(filepath.Join(pmu, "events", event))
+	if err != nil {
+		return 0, err
+	}
+	return parsePMUEventTerms(pmu, desc)
+}
+
+// parsePMUEventTerms translates an event description like
+// "event=0x04,umask=0x03" into the perf config value using the field
+// positions described in the PMU's format directory (e.g. "config:8-15").
+// Terms without a value like "edge" are flags, i.e. get set to 1.
+func parsePMUEventTerms(pmu, desc string) (uint64, error) {
+	var config uint64
+	for _, term := range strings.Split(desc, ",") {
+		kv := strings.SplitN(term, "=", 2)
+		value := uint64(1)
+		if len(kv) == 2 {
+			var err error
+			if value, err = strconv.ParseUint(kv[1], 0, 64); err != nil {
+				return 0, fmt.Errorf("invalid event term %q: %w", term, err)
+			}
+		}
+		format, err := readStringFromFile(filepath.Join(pmu, "format", kv[0]))
+		if err != nil {
+			return 0, err
+		}
+		bits, err := pmuFormatBits(format, value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid event term %q: %w", term, err)
+		}
+		config |= bits
+	}
+	return config, nil
+}
+
+// pmuFormatBits places the given value into the bit ranges of the given
+// format like "config:0-7,32-35". The ranges get filled in the given order,
+// starting with the lowest bits of the value.
+func pmuFormatBits(format string, value uint64) (uint64, error) {
+	if !strings.HasPrefix(format, "config:") {
+		return 0, fmt.Errorf("unsupported format %q", format)
+	}
+	var config uint64
+	for _, r := range strings.Split(strings.TrimPrefix(format, "config:"), ",") {
+		bounds := strings.SplitN(r, "-", 2)
+		first, err := strconv.ParseUint(bounds[0], 10, 6)
+		if err != nil {
+			return 0, fmt.Errorf("invalid format %q: %w", format, err)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(bounds[1], 10, 6); err != nil {
+				return 0, fmt.Errorf("invalid format %q: %w", format, err)
+			}
+		}
+		if last < first {
+			return 0, fmt.Errorf("invalid format %q", format)
+		}
+		width := last - first + 1
+		config |= (value & (1<<width - 1)) << first
+		value >>= width
+	}
+	if value != 0 {
+		return 0, fmt.Errorf("value does not fit into format %q", format)
+	}
+	return config, nil
+}
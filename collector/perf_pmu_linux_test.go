@@ -0,0 +1,48 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import "testing"
+
+func TestParsePMUEventTerms(t *testing.T) {
+	pmu := "fixtures/perf_pmu/uncore_cha_0"
+	for _, tc := range []struct {
+		desc  string
+		want  uint64
+		fails bool
+	}{
+		{desc: "event=0x02,umask=0x0f", want: 0x0f02},
+		{desc: "event=0x35,umask=0xfff", want: 0xf0000ff35},
+		{desc: "event=0x1,edge,thresh=2", want: 0x2040001},
+		{desc: "raw=0xffffffffffffffff", want: 0xffffffffffffffff},
+		{desc: "umask=0x1fff", fails: true},
+		{desc: "event=0x100", fails: true},
+		{desc: "event=foo", fails: true},
+		{desc: "filter_tid=1", fails: true},
+		{desc: "unknown=1", fails: true},
+	} {
+		got, err := parsePMUEventTerms(pmu, tc.desc)
+		if tc.fails {
+			if err == nil {
+				t.Errorf("%s: want error, got %#x", tc.desc, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+		} else if got != tc.want {
+			t.Errorf("%s: want %#x, got %#x", tc.desc, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noperf_sw
+// +build !noperf_sw
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"unsafe"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+// perfSwEvents are the software events to count. Clock events count ns.
+var perfSwEvents = []struct {
+	name   string
+	config uint64
+	scale  float64
+}{
+	{"cpu_clock_seconds", unix.PERF_COUNT_SW_CPU_CLOCK, 1e-9},
+	{"task_clock_seconds", unix.PERF_COUNT_SW_TASK_CLOCK, 1e-9},
+	{"context_switches", unix.PERF_COUNT_SW_CONTEXT_SWITCHES, 1},
+	{"cpu_migrations", unix.PERF_COUNT_SW_CPU_MIGRATIONS, 1},
+	{"page_faults", unix.PERF_COUNT_SW_PAGE_FAULTS, 1},
+	{"minor_page_faults", unix.PERF_COUNT_SW_PAGE_FAULTS_MIN, 1},
+	{"major_page_faults", unix.PERF_COUNT_SW_PAGE_FAULTS_MAJ, 1},
+	{"alignment_faults", unix.PERF_COUNT_SW_ALIGNMENT_FAULTS, 1},
+	{"emulation_faults", unix.PERF_COUNT_SW_EMULATION_FAULTS, 1},
+}
+
+type perfSwCounter struct {
+	fd    int
+	cpu   string
+	event int
+}
+
+type perfSwCollector struct {
+	descs    []typedDesc
+	counters []perfSwCounter
+	logger   log.Logger
+}
+
+func init() {
+	registerCollector("perf-sw", defaultDisabled, NewPerfSwCollector)
+}
+
+// NewPerfSwCollector returns a new Collector exposing software perf event
+// counters per CPU. Unlike hardware PMU events they are available on all
+// architectures and in VMs, however, counting system wide still requires
+// CAP_PERFMON (or CAP_SYS_ADMIN) or kernel.perf_event_paranoid <= 0.
+func NewPerfSwCollector(logger log.Logger) (Collector, error) {
+	c := &perfSwCollector{logger: logger}
+	for _, event := range perfSwEvents {
+		c.descs = append(c.descs, typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "perf_sw", event.name+"_total"),
+			fmt.Sprintf("Software perf event %s of the CPU.", event.name),
+			[]string{"cpu"}, nil,
+		), prometheus.CounterValue})
+	}
+
+	online, err := readStringFromFile(sysFilePath("devices/system/cpu/online"))
+	if err != nil {
+		return nil, err
+	}
+	cpuSet, err := parseCPUList(online)
+	if err != nil {
+		return nil, err
+	}
+	cpus := make([]int, 0, len(cpuSet))
+	for cpu := range cpuSet {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+
+	for _, cpu := range cpus {
+		for i, event := range perfSwEvents {
+			attr := unix.PerfEventAttr{
+				Type:   unix.PERF_TYPE_SOFTWARE,
+				Config: event.config,
+			}
+			attr.Size = uint32(unsafe.Sizeof(attr))
+			fd, err := unix.PerfEventOpen(&attr, -1, cpu, -1, unix.PERF_FLAG_FD_CLOEXEC)
+			if err != nil {
+				c.Close()
+				if errors.Is(err, unix.EACCES) || errors.Is(err, unix.EPERM) {
+					level.Debug(logger).Log("msg", "Insufficient privileges to open software perf events", "err", err)
+					return c, nil
+				}
+				return nil, fmt.Errorf("failed to open perf event %s on CPU %d: %w", event.name, cpu, err)
+			}
+			c.counters = append(c.counters, perfSwCounter{fd, strconv.Itoa(cpu), i})
+		}
+	}
+	return c, nil
+}
+
+// Close implements closer.
+func (c *perfSwCollector) Close() error {
+	for _, counter := range c.counters {
+		unix.Close(counter.fd)
+	}
+	c.counters = nil
+	return nil
+}
+
+// Update implements Collector.
+func (c *perfSwCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.counters) == 0 {
+		level.Debug(c.logger).Log("msg", "No software perf events available")
+		return ErrNoData
+	}
+
+	// the kernel writes the counter in host byte order
+	var raw uint64
+	buf := (*[8]byte)(unsafe.Pointer(&raw))[:]
+	for _, counter := range c.counters {
+		if _, err := unix.Read(counter.fd, buf); err != nil {
+			return err
+		}
+		value := float64(raw) * perfSwEvents[counter.event].scale
+		ch <- c.descs[counter.event].mustNewConstMetric(value, counter.cpu)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nopmem
+// +build !nopmem
+
+package collector
+
+import (
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pmemCollector struct {
+	info, mediaTemp, controllerTemp, dirty, unsafe typedDesc
+	logger                                         log.Logger
+}
+
+func init() {
+	registerCollector("pmem", defaultDisabled, NewPmemCollector)
+}
+
+// NewPmemCollector returns a new Collector exposing the health of NVDIMMs.
+func NewPmemCollector(logger log.Logger) (Collector, error) {
+	return &pmemCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pmem", "info"),
+			"Information about the NVDIMM.",
+			[]string{"device", "dimm_id", "type"}, nil,
+		), prometheus.GaugeValue},
+		mediaTemp: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pmem", "media_temp_celsius"),
+			"Temperature of the NVDIMM media.",
+			[]string{"device"}, nil,
+		), prometheus.GaugeValue},
+		controllerTemp: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pmem", "controller_temp_celsius"),
+			"Temperature of the NVDIMM controller.",
+			[]string{"device"}, nil,
+		), prometheus.GaugeValue},
+		dirty: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pmem", "dirty_shutdowns_total"),
+			"Number of dirty shutdowns of the NVDIMM.",
+			[]string{"device"}, nil,
+		), prometheus.CounterValue},
+		unsafe: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "pmem", "unsafe_shutdowns_total"),
+			"Number of unsafe shutdowns of the NVDIMM.",
+			[]string{"device"}, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the NFIT (ACPI NVDIMM firmware
+// interface table) attributes of the devices on the nd bus. Attributes not
+// provided by the firmware get skipped.
+func (c *pmemCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("bus/nd/devices/*/nfit"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No NVDIMMs found")
+		return ErrNoData
+	}
+
+	for _, dir := range dirs {
+		device := filepath.Base(filepath.Dir(dir))
+		id, _ := readStringFromFile(filepath.Join(dir, "id"))
+		devtype, _ := readStringFromFile(filepath.Join(dir, "..", "devtype"))
+		ch <- c.info.mustNewConstMetric(1, device, id, devtype)
+
+		for _, s := range []struct {
+			files []string
+			desc  typedDesc
+		}{
+			{[]string{"media_temperature"}, c.mediaTemp},
+			{[]string{"controller_temperature"}, c.controllerTemp},
+			// the kernel names it dirty_shutdown
+			{[]string{"dirty_shutdown_count", "dirty_shutdown"}, c.dirty},
+			{[]string{"unsafe_shutdown_count"}, c.unsafe},
+		} {
+			for _, file := range s.files {
+				if value, err := readUintFromFile(filepath.Join(dir, file)); err == nil {
+					ch <- s.desc.mustNewConstMetric(float64(value), device)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
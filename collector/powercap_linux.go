@@ -0,0 +1,98 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nopowercap
+// +build !nopowercap
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const powercapSubsystem = "powercap"
+
+type powercapCollector struct {
+	powerLimit, timeWindow typedDesc
+	logger                 log.Logger
+}
+
+func init() {
+	registerCollector("powercap", defaultDisabled, NewPowercapCollector)
+}
+
+// NewPowercapCollector returns a new Collector exposing the power limits of
+// RAPL zones.
+func NewPowercapCollector(logger log.Logger) (Collector, error) {
+	labels := []string{"zone", "constraint"}
+	return &powercapCollector{
+		powerLimit: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, powercapSubsystem, "constraint_power_limit_watts"),
+			"Power limit of the zone's constraint.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		timeWindow: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, powercapSubsystem, "constraint_time_window_seconds"),
+			"Time window the power limit of the zone's constraint gets averaged over.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the constraints of all powercap
+// zones like intel-rapl:0 (package) or intel-rapl:0:1 (its dram sub-zone).
+func (c *powercapCollector) Update(ch chan<- prometheus.Metric) error {
+	limits, err := filepath.Glob(sysFilePath("class/powercap/*/constraint_*_power_limit_uw"))
+	if err != nil {
+		return err
+	}
+	if len(limits) == 0 {
+		level.Debug(c.logger).Log("msg", "No powercap zones found")
+		return ErrNoData
+	}
+
+	for _, limit := range limits {
+		dir := filepath.Dir(limit)
+		zone := filepath.Base(dir)
+		// constraint_<n>_power_limit_uw
+		prefix := strings.TrimSuffix(filepath.Base(limit), "_power_limit_uw")
+		constraint, err := readStringFromFile(filepath.Join(dir, prefix+"_name"))
+		if err != nil {
+			constraint = strings.TrimPrefix(prefix, "constraint_")
+		}
+
+		uw, err := readUintFromFile(limit)
+		if err != nil {
+			return err
+		}
+		ch <- c.powerLimit.mustNewConstMetric(float64(uw)/1e6, zone, constraint)
+
+		us, err := readUintFromFile(filepath.Join(dir, prefix+"_time_window_us"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		ch <- c.timeWindow.mustNewConstMetric(float64(us)/1e6, zone, constraint)
+	}
+	return nil
+}
@@ -22,26 +22,40 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"syscall"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 var (
-	psiResources = []string{"cpu", "io", "memory"}
+	psiResources     = kingpin.Flag("collector.pressure.resources", "Comma separated list of resources to report pressure stall information for. Resources without a /proc/pressure/<resource> file get skipped.").Default("cpu,io,memory").String()
+	psiAverages      = kingpin.Flag("collector.pressure.averages", "Expose the kernel computed avg10, avg60 and avg300 stall percentages as node_psi_avg_ratio as well.").Bool()
+	psiLegacyMetrics = kingpin.Flag("collector.pressure.legacy-metrics", "DEPRECATED: Expose node_psi_<resource>_{some,full}_us in addition to node_psi_total_stall_us. Will be removed in 2.0.0.").Default("true").Bool()
 )
 
+// psiLegacyTypes are the types by resource of the legacy metrics, i.e. the
+// ones exposed before node_psi_total_stall_us got introduced.
+var psiLegacyTypes = map[string][]string{
+	"cpu":    {"some"},
+	"io":     {"some", "full"},
+	"memory": {"some", "full"},
+}
+
+// psiAvgWindows are the window label values of the avg<N> fields.
+var psiAvgWindows = [3]string{"10s", "60s", "300s"}
+
 type pressureStatsCollector struct {
-	cpu     *prometheus.Desc
-	io      *prometheus.Desc
-	ioFull  *prometheus.Desc
-	mem     *prometheus.Desc
-	memFull *prometheus.Desc
+	total  *prometheus.Desc
+	avg    *prometheus.Desc
+	legacy map[string]*prometheus.Desc
 
-	fs procfs.FS
+	fs        procfs.FS
+	resources []string
 
 	logger log.Logger
 }
@@ -57,40 +71,63 @@ func NewPressureStatsCollector(logger log.Logger) (Collector, error) {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
 
+	resources := make([]string, 0)
+	for _, res := range strings.Split(*psiResources, ",") {
+		res = strings.TrimSpace(res)
+		if res == "" {
+			continue
+		}
+		if _, err := os.Stat(procFilePath("pressure/" + res)); err != nil {
+			level.Debug(logger).Log("msg", "skipping resource without pressure information", "resource", res, "err", err)
+			continue
+		}
+		resources = append(resources, res)
+	}
+
+	var legacy map[string]*prometheus.Desc
+	if *psiLegacyMetrics {
+		legacy = make(map[string]*prometheus.Desc)
+		for _, res := range resources {
+			for _, typ := range psiLegacyTypes[res] {
+				legacy[res+"_"+typ] = prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "psi", res+"_"+typ+"_us"),
+					"DEPRECATED: use node_psi_total_stall_us{resource=\""+res+"\",type=\""+typ+"\"}.",
+					nil, nil,
+				)
+			}
+		}
+	}
+
+	var avg *prometheus.Desc
+	if *psiAverages {
+		avg = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "psi", "avg_ratio"),
+			"Share of time (0-1) in which at least some (type some) or all non-idle (type full) tasks were stalled on the resource simultaneously, averaged over the window by the kernel",
+			[]string{"resource", "type", "window"}, nil,
+		)
+	}
+
 	return &pressureStatsCollector{
-		cpu: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "psi", "cpu_some_us"),
-			"Total share of time in µs in which at least some tasks are stalled on CPU time",
-			nil, nil,
-		),
-		io: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "psi", "io_some_us"),
-			"Total share of time in µs at least some tasks are stalled on IO",
-			nil, nil,
-		),
-		ioFull: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "psi", "io_full_us"),
-			"Total share of time in µs in which all non-idle tasks are stalled on IO simultaneously",
-			nil, nil,
+		total: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "psi", "total_stall_us"),
+			"Total share of time in µs in which at least some (type some) or all non-idle (type full) tasks are stalled on the resource simultaneously",
+			[]string{"resource", "type"}, nil,
 		),
-		mem: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "psi", "memory_some_us"),
-			"Total share of time in µs at least some tasks are stalled on memory",
-			nil, nil,
-		),
-		memFull: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "psi", "memory_full_us"),
-			"Total share of time in µs in which all non-idle tasks are stalled on memory simultaneously",
-			nil, nil,
-		),
-		fs:     fs,
-		logger: logger,
+		avg:       avg,
+		legacy:    legacy,
+		fs:        fs,
+		resources: resources,
+		logger:    logger,
 	}, nil
 }
 
 // Update calls procfs.NewPSIStatsForResource for the different resources and updates the values
 func (c *pressureStatsCollector) Update(ch chan<- prometheus.Metric) error {
-	for _, res := range psiResources {
+	if len(c.resources) == 0 {
+		level.Debug(c.logger).Log("msg", "pressure information is unavailable, you need a Linux kernel >= 4.20 and/or CONFIG_PSI enabled for your kernel")
+		return ErrNoData
+	}
+	for _, res := range c.resources {
 		level.Debug(c.logger).Log("msg", "collecting statistics for resource", "resource", res)
 		vals, err := c.fs.PSIStatsForResource(res)
 		if err != nil {
@@ -104,19 +141,28 @@ func (c *pressureStatsCollector) Update(ch chan<- prometheus.Metric) error {
 			}
 			return fmt.Errorf("failed to retrieve pressure stats: %w", err)
 		}
-		switch res {
-		case "cpu":
-			ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.CounterValue, float64(vals.Some))
-		case "io":
-			ch <- prometheus.MustNewConstMetric(c.io, prometheus.CounterValue, float64(vals.Some))
-			ch <- prometheus.MustNewConstMetric(c.ioFull, prometheus.CounterValue, float64(vals.Full))
-		case "memory":
-			ch <- prometheus.MustNewConstMetric(c.mem, prometheus.CounterValue, float64(vals.Some))
-			ch <- prometheus.MustNewConstMetric(c.memFull, prometheus.CounterValue, float64(vals.Full))
-		default:
-			level.Debug(c.logger).Log("msg", "did not account for resource", "resource", res)
+		c.emit(ch, res, "some", vals.Some, vals.SomeAvg)
+		// Linux >= 5.13 reports full CPU pressure as well
+		if vals.HasFull {
+			c.emit(ch, res, "full", vals.Full, vals.FullAvg)
 		}
 	}
 
 	return nil
 }
+
+// emit sends the given stall time as node_psi_total_stall_us and, as long as
+// the legacy metrics are enabled, as node_psi_<res>_<typ>_us. If enabled, the
+// averages get sent as node_psi_avg_ratio.
+func (c *pressureStatsCollector) emit(ch chan<- prometheus.Metric, res, typ string, value int64, avg [3]float64) {
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.CounterValue, float64(value), res, typ)
+	if c.avg != nil {
+		for i, window := range psiAvgWindows {
+			// the kernel reports percentages
+			ch <- prometheus.MustNewConstMetric(c.avg, prometheus.GaugeValue, avg[i]/100, res, typ, window)
+		}
+	}
+	if desc, ok := c.legacy[res+"_"+typ]; ok {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value))
+	}
+}
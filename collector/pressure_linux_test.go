@@ -0,0 +1,121 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nopressure
+// +build !nopressure
+
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newTestPressureCollector returns a pressure collector reading the given io
+// pressure file content.
+func newTestPressureCollector(t *testing.T, dir, content string) Collector {
+	if err := os.MkdirAll(filepath.Join(dir, "pressure"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "pressure", "io"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	*procPath = dir
+	*psiResources = "io"
+	c, err := NewPressureStatsCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPressureAverages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pressure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	*psiAverages = true
+	*psiLegacyMetrics = false
+	defer func() { *psiAverages = false }()
+
+	c := newTestPressureCollector(t, dir, `some avg10=50.00 avg60=25.00 avg300=12.50 total=159886802
+full avg10=1.00 avg60=0.00 avg300=100.00 total=159229614
+`)
+	want := `# HELP node_psi_avg_ratio Share of time (0-1) in which at least some (type some) or all non-idle (type full) tasks were stalled on the resource simultaneously, averaged over the window by the kernel
+# TYPE node_psi_avg_ratio gauge
+node_psi_avg_ratio{resource="io",type="full",window="10s"} 0.01
+node_psi_avg_ratio{resource="io",type="full",window="300s"} 1
+node_psi_avg_ratio{resource="io",type="full",window="60s"} 0
+node_psi_avg_ratio{resource="io",type="some",window="10s"} 0.5
+node_psi_avg_ratio{resource="io",type="some",window="300s"} 0.125
+node_psi_avg_ratio{resource="io",type="some",window="60s"} 0.25
+# HELP node_psi_total_stall_us Total share of time in µs in which at least some (type some) or all non-idle (type full) tasks are stalled on the resource simultaneously
+# TYPE node_psi_total_stall_us counter
+node_psi_total_stall_us{resource="io",type="full"} 1.59229614e+08
+node_psi_total_stall_us{resource="io",type="some"} 1.59886802e+08
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	// invalid averages must not be ignored
+	c = newTestPressureCollector(t, dir, "some avg10=x avg60=0.00 avg300=0.00 total=1\n")
+	if err := c.Update(make(chan prometheus.Metric, 10)); err == nil {
+		t.Error("expected error for invalid avg10 value")
+	}
+}
+
+func TestPressureLegacyMetrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pressure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	*psiLegacyMetrics = true
+	defer func() { *psiLegacyMetrics = false }()
+
+	if err := os.MkdirAll(filepath.Join(dir, "pressure"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range []string{"cpu", "irq"} {
+		content := "some avg10=0.00 avg60=0.00 avg300=0.00 total=10\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=20\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, "pressure", res), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*procPath = dir
+	*psiResources = "cpu,irq"
+	c, err := NewPressureStatsCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no node_psi_cpu_full_us and no legacy names for other resources
+	want := `# HELP node_psi_cpu_some_us DEPRECATED: use node_psi_total_stall_us{resource="cpu",type="some"}.
+# TYPE node_psi_cpu_some_us counter
+node_psi_cpu_some_us 10
+`
+	err = testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want),
+		"node_psi_cpu_some_us", "node_psi_cpu_full_us", "node_psi_irq_some_us", "node_psi_irq_full_us")
+	if err != nil {
+		t.Error(err)
+	}
+}
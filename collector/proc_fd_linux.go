@@ -0,0 +1,136 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noproc_fd
+// +build !noproc_fd
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var procFdPIDs = kingpin.Flag("collector.proc-fd.pids", "Comma separated list of PIDs whose open file descriptors should be reported, empty means all processes.").Default("").String()
+
+// procFdAnonTypes maps the targets of anonymous inode fds to the type label.
+var procFdAnonTypes = map[string]string{
+	"anon_inode:[eventfd]":   "eventfd",
+	"anon_inode:[timerfd]":   "timerfd",
+	"anon_inode:[eventpoll]": "epoll",
+	"anon_inode:[signalfd]":  "signalfd",
+	"anon_inode:inotify":     "inotify",
+}
+
+type procFdCollector struct {
+	open   typedDesc
+	pids   []string
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("proc-fd", defaultDisabled, NewProcFdCollector)
+}
+
+// NewProcFdCollector returns a new Collector exposing the open file
+// descriptors of processes by type.
+func NewProcFdCollector(logger log.Logger) (Collector, error) {
+	var pids []string
+	for _, pid := range strings.Split(*procFdPIDs, ",") {
+		pid = strings.TrimSpace(pid)
+		if pid == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(pid, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid PID %q in --collector.proc-fd.pids", pid)
+		}
+		pids = append(pids, pid)
+	}
+	return &procFdCollector{
+		open: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "process", "open_fds"),
+			"Number of open file descriptors of the process by type.",
+			[]string{"pid", "type"}, nil,
+		), prometheus.GaugeValue},
+		pids:   pids,
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The type of a file descriptor gets derived
+// from the target of its /proc/<pid>/fd/<n> link, because fdinfo shows the
+// position and flags only.
+func (c *procFdCollector) Update(ch chan<- prometheus.Metric) error {
+	pids := c.pids
+	if len(pids) == 0 {
+		dirs, err := filepath.Glob(procFilePath("[0-9]*"))
+		if err != nil {
+			return err
+		}
+		for _, dir := range dirs {
+			pids = append(pids, filepath.Base(dir))
+		}
+	}
+
+	for _, pid := range pids {
+		dir := procFilePath(pid + "/fd")
+		f, err := os.Open(dir)
+		if err != nil {
+			// process is gone or not accessible
+			level.Debug(c.logger).Log("msg", "Failed to open fd directory", "pid", pid, "err", err)
+			continue
+		}
+		fds, err := f.Readdirnames(-1)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		counts := make(map[string]int)
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(dir, fd))
+			if err != nil {
+				continue
+			}
+			counts[procFdType(target)]++
+		}
+		for typ, n := range counts {
+			ch <- c.open.mustNewConstMetric(float64(n), pid, typ)
+		}
+	}
+	return nil
+}
+
+// procFdType returns the type of the file descriptor with the given link
+// target, e.g. "socket" for "socket:[12345]".
+func procFdType(target string) string {
+	switch {
+	case strings.HasPrefix(target, "/"):
+		return "regular"
+	case strings.HasPrefix(target, "socket:"):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:"):
+		return "pipe"
+	}
+	if typ, ok := procFdAnonTypes[target]; ok {
+		return typ
+	}
+	return "other"
+}
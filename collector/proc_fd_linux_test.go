@@ -0,0 +1,38 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noproc_fd
+// +build !noproc_fd
+
+package collector
+
+import "testing"
+
+func TestProcFdType(t *testing.T) {
+	for target, want := range map[string]string{
+		"/var/log/syslog":        "regular",
+		"/dev/null":              "regular",
+		"socket:[123456]":        "socket",
+		"pipe:[98765]":           "pipe",
+		"anon_inode:[eventfd]":   "eventfd",
+		"anon_inode:[timerfd]":   "timerfd",
+		"anon_inode:[eventpoll]": "epoll",
+		"anon_inode:[signalfd]":  "signalfd",
+		"anon_inode:inotify":     "inotify",
+		"anon_inode:[io_uring]":  "other",
+	} {
+		if got := procFdType(target); got != want {
+			t.Errorf("%s: want %s, got %s", target, want, got)
+		}
+	}
+}
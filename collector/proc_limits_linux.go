@@ -0,0 +1,100 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noproc_limits
+// +build !noproc_limits
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Columns are separated by at least 2 spaces, limit names by a single one.
+var procLimitsSeparator = regexp.MustCompile(`\s{2,}`)
+
+type procLimitsCollector struct {
+	soft, hard typedDesc
+	logger     log.Logger
+}
+
+func init() {
+	registerCollector("proc_limits", defaultEnabled, NewProcLimitsCollector)
+}
+
+// NewProcLimitsCollector returns a new Collector exposing the resource
+// limits of the node_exporter process.
+func NewProcLimitsCollector(logger log.Logger) (Collector, error) {
+	return &procLimitsCollector{
+		soft: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "process", "resource_soft_limit"),
+			"Soft resource limit of the node_exporter process.",
+			[]string{"resource"}, nil,
+		), prometheus.GaugeValue},
+		hard: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "process", "resource_hard_limit"),
+			"Hard resource limit of the node_exporter process.",
+			[]string{"resource"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes /proc/self/limits. Unlimited
+// resources are reported as math.MaxFloat64.
+func (c *procLimitsCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("self/limits"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := procLimitsSeparator.Split(strings.TrimSpace(scanner.Text()), -1)
+		if len(fields) < 3 {
+			return fmt.Errorf("couldn't parse %s line %q", file.Name(), scanner.Text())
+		}
+		// e.g. "Max open files" -> "max_open_files"
+		resource := strings.ReplaceAll(strings.ToLower(fields[0]), " ", "_")
+		soft, err := parseProcLimit(fields[1])
+		if err != nil {
+			return fmt.Errorf("couldn't parse %s line %q: %w", file.Name(), scanner.Text(), err)
+		}
+		hard, err := parseProcLimit(fields[2])
+		if err != nil {
+			return fmt.Errorf("couldn't parse %s line %q: %w", file.Name(), scanner.Text(), err)
+		}
+		ch <- c.soft.mustNewConstMetric(soft, resource)
+		ch <- c.hard.mustNewConstMetric(hard, resource)
+	}
+	return scanner.Err()
+}
+
+func parseProcLimit(s string) (float64, error) {
+	if s == "unlimited" {
+		return math.MaxFloat64, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
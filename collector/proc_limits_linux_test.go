@@ -0,0 +1,73 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noproc_limits
+// +build !noproc_limits
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestProcLimitsCollector(t *testing.T) {
+	*procPath = "fixtures/proc"
+	c, err := NewProcLimitsCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_process_resource_hard_limit Hard resource limit of the node_exporter process.
+# TYPE node_process_resource_hard_limit gauge
+node_process_resource_hard_limit{resource="max_address_space"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_core_file_size"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_cpu_time"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_data_size"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_file_locks"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_file_size"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_locked_memory"} 8.388608e+06
+node_process_resource_hard_limit{resource="max_msgqueue_size"} 819200
+node_process_resource_hard_limit{resource="max_nice_priority"} 0
+node_process_resource_hard_limit{resource="max_open_files"} 524288
+node_process_resource_hard_limit{resource="max_pending_signals"} 62898
+node_process_resource_hard_limit{resource="max_processes"} 62898
+node_process_resource_hard_limit{resource="max_realtime_priority"} 0
+node_process_resource_hard_limit{resource="max_realtime_timeout"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_resident_set"} 1.7976931348623157e+308
+node_process_resource_hard_limit{resource="max_stack_size"} 1.7976931348623157e+308
+# HELP node_process_resource_soft_limit Soft resource limit of the node_exporter process.
+# TYPE node_process_resource_soft_limit gauge
+node_process_resource_soft_limit{resource="max_address_space"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_core_file_size"} 0
+node_process_resource_soft_limit{resource="max_cpu_time"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_data_size"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_file_locks"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_file_size"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_locked_memory"} 8.388608e+06
+node_process_resource_soft_limit{resource="max_msgqueue_size"} 819200
+node_process_resource_soft_limit{resource="max_nice_priority"} 0
+node_process_resource_soft_limit{resource="max_open_files"} 1024
+node_process_resource_soft_limit{resource="max_pending_signals"} 62898
+node_process_resource_soft_limit{resource="max_processes"} 62898
+node_process_resource_soft_limit{resource="max_realtime_priority"} 0
+node_process_resource_soft_limit{resource="max_realtime_timeout"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_resident_set"} 1.7976931348623157e+308
+node_process_resource_soft_limit{resource="max_stack_size"} 8.388608e+06
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
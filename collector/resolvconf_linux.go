@@ -0,0 +1,133 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noresolvconf
+// +build !noresolvconf
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The resolver uses up to 3 nameservers (MAXNS in <resolv.h>).
+const resolvconfMaxNameservers = 3
+
+type resolvconf struct {
+	nameservers, search []string
+	domain              string
+}
+
+type resolvconfCollector struct {
+	nameservers, search, info typedDesc
+	logger                    log.Logger
+
+	mu    sync.Mutex
+	mtime time.Time
+	conf  resolvconf
+}
+
+func init() {
+	registerCollector("resolvconf", defaultDisabled, NewResolvconfCollector)
+}
+
+// NewResolvconfCollector returns a new Collector exposing the DNS resolver
+// configuration.
+func NewResolvconfCollector(logger log.Logger) (Collector, error) {
+	return &resolvconfCollector{
+		nameservers: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "resolvconf", "nameservers_total"),
+			"Number of nameservers configured in /etc/resolv.conf.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		search: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "resolvconf", "search_domains_total"),
+			"Number of search domains configured in /etc/resolv.conf.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "resolvconf", "info"),
+			"The first 3 nameservers and the local domain configured in /etc/resolv.conf.",
+			[]string{"nameserver1", "nameserver2", "nameserver3", "domain"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The file gets parsed only if its
+// modification time changed.
+func (c *resolvconfCollector) Update(ch chan<- prometheus.Metric) error {
+	path := rootfsFilePath("etc/resolv.conf")
+	fi, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "No resolver configuration found", "file", path)
+			return ErrNoData
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !fi.ModTime().Equal(c.mtime) {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		conf, err := parseResolvconf(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		c.conf, c.mtime = conf, fi.ModTime()
+	}
+
+	ch <- c.nameservers.mustNewConstMetric(float64(len(c.conf.nameservers)))
+	ch <- c.search.mustNewConstMetric(float64(len(c.conf.search)))
+	labels := make([]string, resolvconfMaxNameservers, resolvconfMaxNameservers+1)
+	copy(labels, c.conf.nameservers)
+	ch <- c.info.mustNewConstMetric(1, append(labels, c.conf.domain)...)
+	return nil
+}
+
+// parseResolvconf parses the given resolv.conf(5) content. As the resolver
+// does, the last domain or search line wins.
+func parseResolvconf(r io.Reader) (resolvconf, error) {
+	var conf resolvconf
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			conf.nameservers = append(conf.nameservers, fields[1])
+		case "domain":
+			conf.domain = fields[1]
+		case "search":
+			conf.search = fields[1:]
+		}
+	}
+	return conf, scanner.Err()
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noresolvconf
+// +build !noresolvconf
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseResolvconf(t *testing.T) {
+	conf, err := parseResolvconf(strings.NewReader(`# generated
+domain example.com
+search old.example.com
+search a.example.com b.example.com
+; nameserver 10.0.0.1
+nameserver 192.0.2.1
+nameserver 2001:db8::1
+options ndots:2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := resolvconf{
+		nameservers: []string{"192.0.2.1", "2001:db8::1"},
+		search:      []string{"a.example.com", "b.example.com"},
+		domain:      "example.com",
+	}
+	if !reflect.DeepEqual(want, conf) {
+		t.Errorf("want %+v, got %+v", want, conf)
+	}
+}
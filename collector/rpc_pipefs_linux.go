@@ -0,0 +1,84 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !norpc_pipefs
+// +build !norpc_pipefs
+
+package collector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rpcPipefsMountPoints are the usual mount points of rpc_pipefs, the first
+// accessible one gets used.
+var rpcPipefsMountPoints = []string{"var/lib/nfs/rpc_pipefs", "run/rpc_pipefs"}
+
+type rpcPipefsCollector struct {
+	services, mounted typedDesc
+	logger            log.Logger
+}
+
+func init() {
+	registerCollector("rpc_pipefs", defaultDisabled, NewRPCPipefsCollector)
+}
+
+// NewRPCPipefsCollector returns a new Collector exposing the number of pipes
+// in rpc_pipefs per service.
+func NewRPCPipefsCollector(logger log.Logger) (Collector, error) {
+	return &rpcPipefsCollector{
+		services: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rpc_pipefs", "services_total"),
+			"Number of entries in the rpc_pipefs directory of the service.",
+			[]string{"service"}, nil,
+		), prometheus.GaugeValue},
+		mounted: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rpc_pipefs", "mounted"),
+			"Whether rpc_pipefs is accessible (1) or not (0).",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The entries of a service directory vanish
+// when its daemon (e.g. rpc.gssd or rpc.idmapd) stops.
+func (c *rpcPipefsCollector) Update(ch chan<- prometheus.Metric) error {
+	for _, mp := range rpcPipefsMountPoints {
+		root := rootfsFilePath(mp)
+		services, err := ioutil.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		ch <- c.mounted.mustNewConstMetric(1)
+		for _, service := range services {
+			if !service.IsDir() {
+				continue
+			}
+			entries, err := ioutil.ReadDir(filepath.Join(root, service.Name()))
+			if err != nil {
+				level.Debug(c.logger).Log("msg", "Failed to read rpc_pipefs directory", "service", service.Name(), "err", err)
+				continue
+			}
+			ch <- c.services.mustNewConstMetric(float64(len(entries)), service.Name())
+		}
+		return nil
+	}
+	ch <- c.mounted.mustNewConstMetric(0)
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nortnl_link
+// +build !nortnl_link
+
+package collector
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/mdlayher/netlink"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+// sizeof(struct ifinfomsg), which precedes the attributes of RTM_NEWLINK
+// messages.
+const ifInfoMsgLen = 16
+
+type rtnlLinkCollector struct {
+	carrierChanges, carrierUp, carrierDown, promiscuous, txQueueLen typedDesc
+	logger                                                          log.Logger
+}
+
+func init() {
+	registerCollector("rtnl_link", defaultDisabled, NewRtnlLinkCollector)
+}
+
+// NewRtnlLinkCollector returns a new Collector exposing network interface
+// attributes obtained via rtnetlink. The carrier counters and the transmit
+// queue length are exposed by the netclass collector as well, so all metrics
+// use the rtnl_link subsystem to not clash with them. This allows one to get
+// them on hosts without (a usable) sysfs, e.g. in containers.
+func NewRtnlLinkCollector(logger log.Logger) (Collector, error) {
+	desc := func(name, help string, valueType prometheus.ValueType) typedDesc {
+		return typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rtnl_link", name),
+			help,
+			[]string{"device"}, nil,
+		), valueType}
+	}
+	return &rtnlLinkCollector{
+		carrierChanges: desc("carrier_changes_total", "Number of carrier changes of the interface.", prometheus.CounterValue),
+		carrierUp:      desc("carrier_up_changes_total", "Number of times the carrier of the interface went up.", prometheus.CounterValue),
+		carrierDown:    desc("carrier_down_changes_total", "Number of times the carrier of the interface went down.", prometheus.CounterValue),
+		promiscuous:    desc("promiscuous", "Whether the interface is in promiscuous mode (1) or not (0).", prometheus.GaugeValue),
+		txQueueLen:     desc("transmit_queue_length", "Transmit queue length of the interface.", prometheus.GaugeValue),
+		logger:         logger,
+	}, nil
+}
+
+// Update implements Collector. The interface counters (IFLA_STATS64) are not
+// exposed, the netdev collector provides them already.
+func (c *rtnlLinkCollector) Update(ch chan<- prometheus.Metric) error {
+	conn, err := netlink.Dial(unix.NETLINK_ROUTE, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open rtnetlink socket: %w", err)
+	}
+	defer conn.Close()
+
+	msgs, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  unix.RTM_GETLINK,
+			Flags: netlink.Request | netlink.Dump,
+		},
+		Data: make([]byte, ifInfoMsgLen),
+	})
+	if err != nil {
+		return fmt.Errorf("RTM_GETLINK failed: %w", err)
+	}
+
+	for _, m := range msgs {
+		if m.Header.Type != unix.RTM_NEWLINK || len(m.Data) < ifInfoMsgLen {
+			continue
+		}
+		ad, err := netlink.NewAttributeDecoder(m.Data[ifInfoMsgLen:])
+		if err != nil {
+			return err
+		}
+		var name string
+		attrs := map[uint16]uint32{}
+		for ad.Next() {
+			switch t := ad.Type(); t {
+			case unix.IFLA_IFNAME:
+				name = ad.String()
+			case unix.IFLA_TXQLEN, unix.IFLA_PROMISCUITY, unix.IFLA_CARRIER_CHANGES,
+				unix.IFLA_CARRIER_UP_COUNT, unix.IFLA_CARRIER_DOWN_COUNT:
+				attrs[t] = ad.Uint32()
+			}
+		}
+		if err := ad.Err(); err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+
+		for t, desc := range map[uint16]typedDesc{
+			unix.IFLA_CARRIER_CHANGES:    c.carrierChanges,
+			unix.IFLA_CARRIER_UP_COUNT:   c.carrierUp,
+			unix.IFLA_CARRIER_DOWN_COUNT: c.carrierDown,
+			unix.IFLA_TXQLEN:             c.txQueueLen,
+		} {
+			if v, ok := attrs[t]; ok {
+				ch <- desc.mustNewConstMetric(float64(v), name)
+			}
+		}
+		// IFLA_PROMISCUITY is a reference counter
+		if v, ok := attrs[unix.IFLA_PROMISCUITY]; ok {
+			promisc := 0.0
+			if v > 0 {
+				promisc = 1
+			}
+			ch <- c.promiscuous.mustNewConstMetric(promisc, name)
+		}
+	}
+	return nil
+}
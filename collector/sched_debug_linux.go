@@ -0,0 +1,137 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosched_debug
+// +build !nosched_debug
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type schedDebugCollector struct {
+	descs  map[string]typedDesc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("sched_debug", defaultDisabled, NewSchedDebugCollector)
+}
+
+// NewSchedDebugCollector returns a new Collector exposing the per CPU run
+// queue stats of the scheduler debug file.
+func NewSchedDebugCollector(logger log.Logger) (Collector, error) {
+	desc := func(name, help string, valueType prometheus.ValueType) typedDesc {
+		return typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "scheddbg", name),
+			help, []string{"cpu"}, nil,
+		), valueType}
+	}
+	return &schedDebugCollector{
+		descs: map[string]typedDesc{
+			"nr_running":         desc("nr_running", "Number of runnable tasks on the run queue of the CPU.", prometheus.GaugeValue),
+			"load":               desc("load", "Load weight of the run queue of the CPU.", prometheus.GaugeValue),
+			"nr_switches":        desc("nr_switches_total", "Number of context switches on the CPU.", prometheus.CounterValue),
+			"nr_uninterruptible": desc("nr_uninterruptible", "Number of uninterruptible tasks accounted on the CPU. Tasks may sleep on one CPU and wake up on another, so per CPU values can be negative, only the sum is meaningful.", prometheus.GaugeValue),
+		},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Linux >= 5.13 moved /proc/sched_debug to
+// debugfs (sched/debug), which gets used as fallback.
+func (c *schedDebugCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("sched_debug"))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(sysFilePath("kernel/debug/sched/debug"))
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+			level.Debug(c.logger).Log("msg", "Scheduler debug information not available", "err", err)
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseSchedDebug(file, c.descs)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for cpu, values := range stats {
+		for field, value := range values {
+			desc := c.descs[field]
+			ch <- desc.mustNewConstMetric(value, cpu)
+		}
+	}
+	return nil
+}
+
+// parseSchedDebug returns the wanted fields of the "cpu#<N>" sections. The
+// fields of the cfs_rq, rt_rq and dl_rq subsections following them (some
+// with the same names) get ignored:
+//
+//	cpu#0, 2400.000 MHz
+//	  .nr_running                    : 1
+//	  .nr_switches                   : 5827362
+//	  ...
+//	cfs_rq[0]:/
+//	  .nr_running                    : 1
+func parseSchedDebug(r io.Reader, wanted map[string]typedDesc) (map[string]map[string]float64, error) {
+	stats := make(map[string]map[string]float64)
+	var cpu map[string]float64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			cpu = nil
+			if strings.HasPrefix(line, "cpu#") {
+				id := strings.TrimPrefix(strings.SplitN(line, ",", 2)[0], "cpu#")
+				cpu = make(map[string]float64)
+				stats[id] = cpu
+			}
+			continue
+		}
+		if cpu == nil {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		field := strings.TrimPrefix(strings.TrimSpace(parts[0]), ".")
+		if _, ok := wanted[field]; !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", field, err)
+		}
+		cpu[field] = value
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noscsi_generic
+// +build !noscsi_generic
+
+package collector
+
+import (
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const scsiSubsystem = "scsi"
+
+type scsiCollector struct {
+	info, ioRequests, ioDone, ioErrors typedDesc
+	logger                             log.Logger
+}
+
+func init() {
+	registerCollector("scsi_generic", defaultDisabled, NewSCSICollector)
+}
+
+// NewSCSICollector returns a new Collector exposing SCSI disk I/O counters.
+func NewSCSICollector(logger log.Logger) (Collector, error) {
+	labels := []string{"device"}
+	return &scsiCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scsiSubsystem, "device_info"),
+			"Non-numeric data from /sys/class/scsi_disk/<device>/device, value is always 1.",
+			[]string{"device", "vendor", "model"}, nil,
+		), prometheus.GaugeValue},
+		ioRequests: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scsiSubsystem, "io_requests_total"),
+			"Number of I/O requests sent to the SCSI device.",
+			labels, nil,
+		), prometheus.CounterValue},
+		ioDone: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scsiSubsystem, "io_done_total"),
+			"Number of I/O requests completed by the SCSI device.",
+			labels, nil,
+		), prometheus.CounterValue},
+		ioErrors: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, scsiSubsystem, "io_error_total"),
+			"Number of I/O requests to the SCSI device, which failed.",
+			labels, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes SCSI disk I/O counters.
+func (c *scsiCollector) Update(ch chan<- prometheus.Metric) error {
+	disks, err := filepath.Glob(sysFilePath("class/scsi_disk/*"))
+	if err != nil {
+		return err
+	}
+	if len(disks) == 0 {
+		level.Debug(c.logger).Log("msg", "No SCSI disks found")
+		return ErrNoData
+	}
+
+	for _, disk := range disks {
+		device := filepath.Base(disk)
+		dir := filepath.Join(disk, "device")
+		// vendor and model are optional, e.g. for some USB bridges
+		vendor, err := readStringFromFile(filepath.Join(dir, "vendor"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read SCSI device vendor", "device", device, "err", err)
+		}
+		model, err := readStringFromFile(filepath.Join(dir, "model"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read SCSI device model", "device", device, "err", err)
+		}
+
+		stats := []struct {
+			file  string
+			desc  typedDesc
+			value uint64
+		}{
+			{"iorequest_cnt", c.ioRequests, 0},
+			{"iodone_cnt", c.ioDone, 0},
+			{"ioerr_cnt", c.ioErrors, 0},
+		}
+		for i := range stats {
+			if stats[i].value, err = readSCSICounterFromFile(filepath.Join(dir, stats[i].file)); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			// the device may have gone in the meantime
+			level.Debug(c.logger).Log("msg", "Skipping SCSI device", "device", device, "err", err)
+			continue
+		}
+
+		ch <- c.info.mustNewConstMetric(1, device, vendor, model)
+		for _, stat := range stats {
+			ch <- stat.desc.mustNewConstMetric(float64(stat.value), device)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noscsi_sat
+// +build !noscsi_sat
+
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"unsafe"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	sgIO             = 0x2285
+	sgDxferNone      = -1
+	sgDxferFromDev   = -3
+	sgInterfaceID    = 'S'
+	sgTimeoutMs      = 5000
+	smartDataSize    = 512
+	smartAttrOffset  = 2
+	smartAttrSize    = 12
+	smartAttrEntries = 30
+)
+
+// errDiskStandby is returned for disks, which are not queried to not spin
+// them up.
+var errDiskStandby = errors.New("disk is in standby")
+
+// smartAttributes maps the exposed SMART attribute IDs to their metric name.
+var smartAttributes = map[uint8]string{
+	5:   "reallocated_sector_ct",
+	9:   "power_on_hours",
+	12:  "power_cycle_count",
+	187: "reported_uncorrect",
+	194: "temperature_celsius",
+}
+
+// sgIOHdr reflects struct sg_io_hdr of <scsi/sg.h>.
+type sgIOHdr struct {
+	interfaceID    int32
+	dxferDirection int32
+	cmdLen         uint8
+	mxSbLen        uint8
+	iovecCount     uint16
+	dxferLen       uint32
+	dxferp         uintptr
+	cmdp           uintptr
+	sbp            uintptr
+	timeout        uint32
+	flags          uint32
+	packID         int32
+	usrPtr         uintptr
+	status         uint8
+	maskedStatus   uint8
+	msgStatus      uint8
+	sbLenWr        uint8
+	hostStatus     uint16
+	driverStatus   uint16
+	resid          int32
+	duration       uint32
+	info           uint32
+}
+
+type scsiSatCollector struct {
+	descs  map[uint8]typedDesc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("scsi_sat", defaultDisabled, NewScsiSatCollector)
+}
+
+// NewScsiSatCollector returns a new Collector exposing some SMART attributes
+// of ATA disks attached via SCSI/ATA translation (SAT). Requires read access
+// to the /dev/sd* block devices. Disks in standby get skipped, so scrapes
+// do not keep them spinning.
+func NewScsiSatCollector(logger log.Logger) (Collector, error) {
+	c := &scsiSatCollector{
+		descs:  map[uint8]typedDesc{},
+		logger: logger,
+	}
+	for id, name := range smartAttributes {
+		c.descs[id] = typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sat_smart", name),
+			fmt.Sprintf("Raw value of the SMART attribute %d of the disk.", id),
+			[]string{"device"}, nil,
+		), prometheus.GaugeValue}
+	}
+	return c, nil
+}
+
+// Update implements Collector.
+func (c *scsiSatCollector) Update(ch chan<- prometheus.Metric) error {
+	disks, err := filepath.Glob(sysFilePath("block/sd*"))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, disk := range disks {
+		device := filepath.Base(disk)
+		data, err := readSMARTData(rootfsFilePath("dev/" + device))
+		if errors.Is(err, errDiskStandby) {
+			level.Debug(c.logger).Log("msg", "Skipping disk in standby", "device", device)
+			continue
+		}
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read SMART data", "device", device, "err", err)
+			continue
+		}
+		for id, value := range parseSMARTAttributes(data) {
+			if desc, ok := c.descs[id]; ok {
+				ch <- desc.mustNewConstMetric(float64(value), device)
+			}
+		}
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No disks providing SMART data found")
+		return ErrNoData
+	}
+	return nil
+}
+
+// readSMARTData issues an ATA SMART READ DATA command wrapped into an ATA
+// PASS-THROUGH (16) SCSI command to the given device. Since this would spin
+// up a disk in standby, the power mode gets checked first and errDiskStandby
+// returned for sleeping disks.
+func readSMARTData(path string) ([]byte, error) {
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	// If the power mode is unknown, e.g. because the device returns fixed
+	// format sense data, the disk is assumed to be active.
+	if mode, err := checkPowerMode(fd); err == nil && ataPowerModeStandby(mode) {
+		return nil, errDiskStandby
+	}
+
+	cdb := []byte{
+		0x85,       // ATA PASS-THROUGH (16)
+		4 << 1,     // protocol: PIO data-in
+		0x0e,       // t_dir: from device, byt_blok: blocks, t_length: sector count
+		0x00, 0xd0, // features: SMART READ DATA
+		0x00, 0x01, // sector count
+		0x00, 0x00, // lba low
+		0x00, 0x4f, // lba mid
+		0x00, 0xc2, // lba high
+		0x00, // device
+		0xb0, // command: SMART
+		0x00, // control
+	}
+	data := make([]byte, smartDataSize)
+	hdr, _, err := sgIOCommand(fd, cdb, data)
+	if err != nil {
+		return nil, err
+	}
+	if hdr.status != 0 || hdr.hostStatus != 0 || hdr.driverStatus&0x0f != 0 {
+		return nil, fmt.Errorf("SG_IO failed: status %#x, host status %#x, driver status %#x",
+			hdr.status, hdr.hostStatus, hdr.driverStatus)
+	}
+	return data, nil
+}
+
+// checkPowerMode issues an ATA CHECK POWER MODE command, which does not spin
+// up the disk, and returns the power mode reported in the sector count
+// register of the ATA Status Return sense data descriptor.
+func checkPowerMode(fd int) (uint8, error) {
+	cdb := []byte{
+		0x85,       // ATA PASS-THROUGH (16)
+		3 << 1,     // protocol: non-data
+		0x20,       // ck_cond: return the ATA registers as sense data
+		0x00, 0x00, // features
+		0x00, 0x00, // sector count
+		0x00, 0x00, // lba low
+		0x00, 0x00, // lba mid
+		0x00, 0x00, // lba high
+		0x00, // device
+		0xe5, // command: CHECK POWER MODE
+		0x00, // control
+	}
+	_, sense, err := sgIOCommand(fd, cdb, nil)
+	if err != nil {
+		return 0, err
+	}
+	// descriptor format sense data with an ATA Status Return descriptor
+	// (0x09) following the 8 byte header
+	if len(sense) < 8+14 || sense[0]&0x7f != 0x72 || sense[8] != 0x09 {
+		return 0, fmt.Errorf("no ATA status returned")
+	}
+	return sense[8+5], nil
+}
+
+// ataPowerModeStandby tells, whether the given CHECK POWER MODE result means
+// the disk is spun down: standby (0x00), standby_y (0x01) or NV cache power
+// mode with the spindle spun down (0x40, 0x41).
+func ataPowerModeStandby(mode uint8) bool {
+	switch mode {
+	case 0x00, 0x01, 0x40, 0x41:
+		return true
+	}
+	return false
+}
+
+// sgIOCommand sends the given SCSI command via the SG_IO ioctl. If data is
+// not empty, it receives the data transferred from the device. The returned
+// sense data are only valid for the length written by the driver.
+func sgIOCommand(fd int, cdb, data []byte) (sgIOHdr, []byte, error) {
+	sense := make([]byte, 32)
+	hdr := sgIOHdr{
+		interfaceID:    sgInterfaceID,
+		dxferDirection: sgDxferNone,
+		cmdLen:         uint8(len(cdb)),
+		mxSbLen:        uint8(len(sense)),
+		cmdp:           uintptr(unsafe.Pointer(&cdb[0])),
+		sbp:            uintptr(unsafe.Pointer(&sense[0])),
+		timeout:        sgTimeoutMs,
+	}
+	if len(data) != 0 {
+		hdr.dxferDirection = sgDxferFromDev
+		hdr.dxferLen = uint32(len(data))
+		hdr.dxferp = uintptr(unsafe.Pointer(&data[0]))
+	}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), sgIO, uintptr(unsafe.Pointer(&hdr)))
+	runtime.KeepAlive(cdb)
+	runtime.KeepAlive(data)
+	runtime.KeepAlive(sense)
+	if errno != 0 {
+		return hdr, nil, errno
+	}
+	return hdr, sense[:hdr.sbLenWr], nil
+}
+
+// parseSMARTAttributes returns the raw values of the attribute table of the
+// given SMART data by attribute ID.
+func parseSMARTAttributes(data []byte) map[uint8]uint64 {
+	attrs := map[uint8]uint64{}
+	for i := 0; i < smartAttrEntries; i++ {
+		off := smartAttrOffset + i*smartAttrSize
+		if off+smartAttrSize > len(data) {
+			break
+		}
+		entry := data[off : off+smartAttrSize]
+		id := entry[0]
+		if id == 0 {
+			continue
+		}
+		// 48 bit little endian raw value at offset 5
+		raw := make([]byte, 8)
+		copy(raw, entry[5:11])
+		value := binary.LittleEndian.Uint64(raw)
+		if id == 194 {
+			// the upper bytes may contain min/max temperatures
+			value &= 0xff
+		}
+		attrs[id] = value
+	}
+	return attrs
+}
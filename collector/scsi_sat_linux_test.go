@@ -0,0 +1,37 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noscsi_sat
+// +build !noscsi_sat
+
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSMARTAttributes(t *testing.T) {
+	data := make([]byte, smartDataSize)
+	for i, entry := range [][]byte{
+		{5, 0x33, 0, 100, 100, 8, 0, 0, 0, 0, 0, 0},
+		{9, 0x32, 0, 95, 95, 0x10, 0x27, 0, 0, 0, 0, 0},
+		{194, 0x22, 0, 64, 50, 36, 0, 18, 0, 50, 0, 0},
+	} {
+		copy(data[smartAttrOffset+i*smartAttrSize:], entry)
+	}
+	want := map[uint8]uint64{5: 8, 9: 10000, 194: 36}
+	if got := parseSMARTAttributes(data); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
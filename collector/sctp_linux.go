@@ -0,0 +1,77 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosctp
+// +build !nosctp
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type sctpCollector struct {
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("sctp", defaultEnabled, NewSCTPCollector)
+}
+
+// NewSCTPCollector returns a new Collector exposing SCTP MIB counters.
+func NewSCTPCollector(logger log.Logger) (Collector, error) {
+	return &sctpCollector{logger: logger}, nil
+}
+
+// Update implements Collector and exposes /proc/net/sctp/snmp. All fields
+// but SctpCurrEstab are counters.
+func (c *sctpCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/sctp/snmp"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Kernel without SCTP support or sctp module not loaded")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for field, value := range stats {
+		name, valueType := camelCaseToSnake(strings.TrimPrefix(field, "Sctp")), prometheus.CounterValue
+		if field == "SctpCurrEstab" {
+			valueType = prometheus.GaugeValue
+		} else {
+			name += "_total"
+		}
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "sctp", name),
+				fmt.Sprintf("/proc/net/sctp/snmp information field %s.", field),
+				nil, nil),
+			valueType,
+			float64(value),
+		)
+	}
+	return nil
+}
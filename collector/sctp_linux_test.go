@@ -0,0 +1,55 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosctp
+// +build !nosctp
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSCTPCollector(t *testing.T) {
+	*procPath = "fixtures/proc"
+	c, err := NewSCTPCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_sctp_curr_estab /proc/net/sctp/snmp information field SctpCurrEstab.
+# TYPE node_sctp_curr_estab gauge
+node_sctp_curr_estab 5
+# HELP node_sctp_in_sctp_packs_total /proc/net/sctp/snmp information field SctpInSCTPPacks.
+# TYPE node_sctp_in_sctp_packs_total counter
+node_sctp_in_sctp_packs_total 29998
+# HELP node_sctp_out_sctp_packs_total /proc/net/sctp/snmp information field SctpOutSCTPPacks.
+# TYPE node_sctp_out_sctp_packs_total counter
+node_sctp_out_sctp_packs_total 30000
+# HELP node_sctp_t1_init_expireds_total /proc/net/sctp/snmp information field SctpT1InitExpireds.
+# TYPE node_sctp_t1_init_expireds_total counter
+node_sctp_t1_init_expireds_total 3
+`
+	err = testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want),
+		"node_sctp_curr_estab", "node_sctp_in_sctp_packs_total",
+		"node_sctp_out_sctp_packs_total", "node_sctp_t1_init_expireds_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(updateCollector{c}); n != 32 {
+		t.Errorf("want 32 metrics, got %d", n)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosmaps_all
+// +build !nosmaps_all
+
+package collector
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// smapsAllFields maps the smaps_rollup fields to the type label.
+var smapsAllFields = map[string]string{
+	"Pss_Anon:":  "anon",
+	"Pss_File:":  "file",
+	"Pss_Shmem:": "shmem",
+}
+
+type smapsAllCollector struct {
+	pss    typedDesc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("smaps-all", defaultDisabled, NewSmapsAllCollector)
+}
+
+// NewSmapsAllCollector returns a new Collector exposing the proportional
+// set size of all processes summed up by memory type.
+func NewSmapsAllCollector(logger log.Logger) (Collector, error) {
+	return &smapsAllCollector{
+		pss: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "pss_bytes"),
+			"Proportional set size of all processes by memory type.",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. It reads /proc/<pid>/smaps_rollup of each
+// process, which makes the kernel walk all its mappings - so this is
+// expensive on hosts with many or huge processes. The Pss of a process is
+// the sum of the exposed types. Processes gone or not readable by the
+// exporter get skipped.
+func (c *smapsAllCollector) Update(ch chan<- prometheus.Metric) error {
+	files, err := filepath.Glob(procFilePath("[0-9]*/smaps_rollup"))
+	if err != nil {
+		return err
+	}
+
+	pss := make(map[string]uint64, len(smapsAllFields))
+	found := false
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		err = parseSmapsRollup(f, pss)
+		f.Close()
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read smaps_rollup", "file", file, "err", err)
+			continue
+		}
+		found = true
+	}
+	if !found || len(pss) == 0 {
+		level.Debug(c.logger).Log("msg", "No smaps_rollup with Pss details found, needs Linux >= 5.7")
+		return ErrNoData
+	}
+	for typ, value := range pss {
+		ch <- c.pss.mustNewConstMetric(float64(value), typ)
+	}
+	return nil
+}
+
+// parseSmapsRollup adds the Pss_* values of the given smaps_rollup file in
+// bytes to pss.
+func parseSmapsRollup(r io.Reader, pss map[string]uint64) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		typ, ok := smapsAllFields[fields[0]]
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		pss[typ] += value * 1024
+	}
+	return scanner.Err()
+}
@@ -30,6 +30,8 @@ type softnetCollector struct {
 	processed    *prometheus.Desc
 	dropped      *prometheus.Desc
 	timeSqueezed *prometheus.Desc
+	receivedRps  *prometheus.Desc
+	flowLimit    *prometheus.Desc
 	logger       log.Logger
 }
 
@@ -65,6 +67,16 @@ func NewSoftnetCollector(logger log.Logger) (Collector, error) {
 			"Number of times processing packets ran out of quota",
 			[]string{"cpu"}, nil,
 		),
+		receivedRps: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "received_rps_total"),
+			"Number of times the CPU has been woken up by an inter-processor interrupt to process packets (RPS)",
+			[]string{"cpu"}, nil,
+		),
+		flowLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "flow_limit_count_total"),
+			"Number of times the flow limit has been reached",
+			[]string{"cpu"}, nil,
+		),
 		logger: logger,
 	}, nil
 }
@@ -97,6 +109,23 @@ func (c *softnetCollector) Update(ch chan<- prometheus.Metric) error {
 			float64(cpuStats.TimeSqueezed),
 			cpu,
 		)
+		// not available on older kernels
+		if cpuStats.HasRps {
+			ch <- prometheus.MustNewConstMetric(
+				c.receivedRps,
+				prometheus.CounterValue,
+				float64(cpuStats.ReceivedRps),
+				cpu,
+			)
+		}
+		if cpuStats.HasFlowLimitCount {
+			ch <- prometheus.MustNewConstMetric(
+				c.flowLimit,
+				prometheus.CounterValue,
+				float64(cpuStats.FlowLimitCount),
+				cpu,
+			)
+		}
 	}
 
 	return nil
@@ -0,0 +1,91 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosoftnet
+// +build !nosoftnet
+
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSoftnetCollector(t *testing.T) {
+	const common = `# HELP node_softnet_dropped_total Number of dropped packets
+# TYPE node_softnet_dropped_total counter
+node_softnet_dropped_total{cpu="0"} 41
+# HELP node_softnet_processed_total Number of processed packets
+# TYPE node_softnet_processed_total counter
+node_softnet_processed_total{cpu="0"} 916354
+# HELP node_softnet_times_squeezed_total Number of times processing packets ran out of quota
+# TYPE node_softnet_times_squeezed_total counter
+node_softnet_times_squeezed_total{cpu="0"} 10
+`
+	for _, tc := range []struct {
+		name, stat, want string
+	}{
+		{
+			name: "Linux < 2.6.35",
+			stat: "000dfb82 00000029 0000000a 00000000 00000000 00000000 00000000 00000000 00000000\n",
+			want: common,
+		},
+		{
+			name: "Linux < 3.11",
+			stat: "000dfb82 00000029 0000000a 00000000 00000000 00000000 00000000 00000000 00000000 00000005\n",
+			want: common + `# HELP node_softnet_received_rps_total Number of times the CPU has been woken up by an inter-processor interrupt to process packets (RPS)
+# TYPE node_softnet_received_rps_total counter
+node_softnet_received_rps_total{cpu="0"} 5
+`,
+		},
+		{
+			name: "Linux >= 3.11",
+			stat: "000dfb82 00000029 0000000a 00000000 00000000 00000000 00000000 00000000 00000000 00000005 00000002\n",
+			want: common + `# HELP node_softnet_flow_limit_count_total Number of times the flow limit has been reached
+# TYPE node_softnet_flow_limit_count_total counter
+node_softnet_flow_limit_count_total{cpu="0"} 2
+# HELP node_softnet_received_rps_total Number of times the CPU has been woken up by an inter-processor interrupt to process packets (RPS)
+# TYPE node_softnet_received_rps_total counter
+node_softnet_received_rps_total{cpu="0"} 5
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "softnet")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			if err := os.Mkdir(filepath.Join(dir, "net"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, "net", "softnet_stat"), []byte(tc.stat), 0644); err != nil {
+				t.Fatal(err)
+			}
+			*procPath = dir
+			c, err := NewSoftnetCollector(log.NewNopLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(tc.want)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
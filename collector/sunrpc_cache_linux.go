@@ -0,0 +1,113 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosunrpc_cache
+// +build !nosunrpc_cache
+
+package collector
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type sunrpcCacheCollector struct {
+	entries, flushed typedDesc
+	logger           log.Logger
+}
+
+func init() {
+	registerCollector("sunrpc_cache", defaultDisabled, NewSunrpcCacheCollector)
+}
+
+// NewSunrpcCacheCollector returns a new Collector exposing the number of
+// entries and the last flush time of the SunRPC caches, i.e. the
+// /proc/net/rpc/ directories like auth.unix.ip or nfsd.fh.
+func NewSunrpcCacheCollector(logger log.Logger) (Collector, error) {
+	return &sunrpcCacheCollector{
+		entries: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sunrpc_cache", "entries"),
+			"Number of valid entries in the SunRPC cache.",
+			[]string{"cache"}, nil,
+		), prometheus.GaugeValue},
+		flushed: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sunrpc_cache", "flush_timestamp_seconds"),
+			"Unix time of the last flush of the SunRPC cache.",
+			[]string{"cache"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Caches get identified by their flush file.
+// The content files are readable by root only, so the number of entries
+// gets exposed only if readable.
+func (c *sunrpcCacheCollector) Update(ch chan<- prometheus.Metric) error {
+	files, err := filepath.Glob(procFilePath("net/rpc/*/flush"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		level.Debug(c.logger).Log("msg", "No SunRPC caches found")
+		return ErrNoData
+	}
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		cache := filepath.Base(dir)
+		if data, err := ioutil.ReadFile(file); err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read SunRPC cache flush time", "cache", cache, "err", err)
+		} else if v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err != nil {
+			level.Debug(c.logger).Log("msg", "Invalid SunRPC cache flush time", "cache", cache, "err", err)
+		} else {
+			ch <- c.flushed.mustNewConstMetric(float64(v), cache)
+		}
+
+		f, err := os.Open(filepath.Join(dir, "content"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to open SunRPC cache content", "cache", cache, "err", err)
+			continue
+		}
+		n, err := countSunrpcCacheEntries(f)
+		f.Close()
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read SunRPC cache content", "cache", cache, "err", err)
+			continue
+		}
+		ch <- c.entries.mustNewConstMetric(float64(n), cache)
+	}
+	return nil
+}
+
+// countSunrpcCacheEntries returns the number of valid entries of the given
+// cache content. The header, expiry info and negative or expired entries
+// are commented out with a leading '#'.
+func countSunrpcCacheEntries(r io.Reader) (uint64, error) {
+	var n uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && line[0] != '#' {
+			n++
+		}
+	}
+	return n, scanner.Err()
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nosunrpc_cache
+// +build !nosunrpc_cache
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSunrpcCacheCollector(t *testing.T) {
+	*procPath = "fixtures/proc"
+	c, err := NewSunrpcCacheCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_sunrpc_cache_entries Number of valid entries in the SunRPC cache.
+# TYPE node_sunrpc_cache_entries gauge
+node_sunrpc_cache_entries{cache="auth.unix.ip"} 2
+node_sunrpc_cache_entries{cache="nfsd.fh"} 1
+# HELP node_sunrpc_cache_flush_timestamp_seconds Unix time of the last flush of the SunRPC cache.
+# TYPE node_sunrpc_cache_flush_timestamp_seconds gauge
+node_sunrpc_cache_flush_timestamp_seconds{cache="auth.unix.ip"} 1.7e+09
+node_sunrpc_cache_flush_timestamp_seconds{cache="nfsd.fh"} 1.69999e+09
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notcpmem
+// +build !notcpmem
+
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+type tcpMemCollector struct {
+	memory, read, write, allocated typedDesc
+	logger                         log.Logger
+}
+
+func init() {
+	registerCollector("tcpmem", defaultEnabled, NewTCPMemCollector)
+}
+
+// NewTCPMemCollector returns a new Collector exposing the TCP memory limits
+// and usage.
+func NewTCPMemCollector(logger log.Logger) (Collector, error) {
+	return &tcpMemCollector{
+		memory: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "tcp", "memory_pages"),
+			"TCP memory thresholds in pages (net.ipv4.tcp_mem).",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		read: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "tcp", "read_memory_bytes"),
+			"TCP socket receive buffer sizes (net.ipv4.tcp_rmem).",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		write: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "tcp", "write_memory_bytes"),
+			"TCP socket send buffer sizes (net.ipv4.tcp_wmem).",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		allocated: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "tcp", "memory_allocated_pages"),
+			"Number of pages currently allocated by TCP.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. TCP is under memory pressure, if
+// node_tcp_memory_allocated_pages exceeds node_tcp_memory_pages{type="pressure"}.
+func (c *tcpMemCollector) Update(ch chan<- prometheus.Metric) error {
+	for _, s := range []struct {
+		file  string
+		types []string
+		desc  typedDesc
+	}{
+		{"sys/net/ipv4/tcp_mem", []string{"min", "pressure", "max"}, c.memory},
+		{"sys/net/ipv4/tcp_rmem", []string{"min", "default", "max"}, c.read},
+		{"sys/net/ipv4/tcp_wmem", []string{"min", "default", "max"}, c.write},
+	} {
+		values, err := readTCPMemValues(procFilePath(s.file))
+		if err != nil {
+			return err
+		}
+		for i, t := range s.types {
+			ch <- s.desc.mustNewConstMetric(float64(values[i]), t)
+		}
+	}
+
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return fmt.Errorf("failed to open procfs: %w", err)
+	}
+	stat, err := fs.NetSockstat()
+	if err != nil {
+		return fmt.Errorf("failed to get sockstat data: %w", err)
+	}
+	for _, p := range stat.Protocols {
+		if p.Protocol == "TCP" && p.Mem != nil {
+			ch <- c.allocated.mustNewConstMetric(float64(*p.Mem))
+		}
+	}
+	return nil
+}
+
+// readTCPMemValues reads a "<min> <pressure|default> <max>" sysctl file.
+func readTCPMemValues(file string) ([3]uint64, error) {
+	var values [3]uint64
+	data, err := readStringFromFile(file)
+	if err != nil {
+		return values, err
+	}
+	fields := strings.Fields(data)
+	if len(fields) != len(values) {
+		return values, fmt.Errorf("%s: expected %d fields, got %d", file, len(values), len(fields))
+	}
+	for i, field := range fields {
+		if values[i], err = strconv.ParseUint(field, 10, 64); err != nil {
+			return values, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notls
+// +build !notls
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tlsSubsystem = "tls"
+
+// tlsStats maps /proc/net/tls_stat keys to metric names and HELP.
+var tlsStats = []struct {
+	key, name, help string
+}{
+	{"TlsTxSw", "tx_sw_total", "Number of TX sessions initialized with software encryption."},
+	{"TlsRxSw", "rx_sw_total", "Number of RX sessions initialized with software decryption."},
+	{"TlsTxDevice", "tx_device_total", "Number of TX sessions initialized with NIC offload encryption."},
+	{"TlsRxDevice", "rx_device_total", "Number of RX sessions initialized with NIC offload decryption."},
+	{"TlsDecryptError", "decryption_error_total", "Number of records which failed decryption or authentication."},
+}
+
+type tlsCollector struct {
+	descs  []*prometheus.Desc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("tls", defaultEnabled, NewTLSCollector)
+}
+
+// NewTLSCollector returns a new Collector exposing kernel TLS stats.
+func NewTLSCollector(logger log.Logger) (Collector, error) {
+	descs := make([]*prometheus.Desc, len(tlsStats))
+	for i, stat := range tlsStats {
+		descs[i] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, tlsSubsystem, stat.name),
+			stat.help, nil, nil,
+		)
+	}
+	return &tlsCollector{
+		descs:  descs,
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes /proc/net/tls_stat.
+func (c *tlsCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/tls_stat"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "kernel TLS statistics not found, CONFIG_TLS enabled and module tls loaded?")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for i, stat := range tlsStats {
+		if v, ok := stats[stat.key]; ok {
+			ch <- prometheus.MustNewConstMetric(c.descs[i], prometheus.CounterValue, float64(v))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notls
+// +build !notls
+
+package collector
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTLSStats(t *testing.T) {
+	file, err := os.Open("fixtures/proc/net/tls_stat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]uint64{
+		"TlsCurrTxSw":     1,
+		"TlsTxSw":         53,
+		"TlsRxDevice":     5,
+		"TlsDecryptError": 3,
+	} {
+		if got := stats[key]; want != got {
+			t.Errorf("want %s %d, got %d", key, want, got)
+		}
+	}
+
+	if _, err := parseKeyValueStats(strings.NewReader("TlsTxSw 1 2\n")); err == nil {
+		t.Error("expected error for invalid line")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noudev
+// +build !noudev
+
+package collector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+type udevEventKey struct {
+	action    string
+	subsystem string
+}
+
+type udevCollector struct {
+	events typedDesc
+	logger log.Logger
+	socket *os.File
+
+	mtx    sync.Mutex
+	counts map[udevEventKey]uint64
+}
+
+func init() {
+	registerCollector("udev", defaultDisabled, NewUdevCollector)
+}
+
+// NewUdevCollector returns a new Collector exposing the number of device
+// events sent by the kernel. The events get counted in the background from
+// now on.
+func NewUdevCollector(logger log.Logger) (Collector, error) {
+	// non-blocking, so that the runtime poller gets used and Close unblocks
+	// the receiver
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open uevent socket: %w", err)
+	}
+	// group 1 are the kernel events, group 2 the ones re-broadcasted by udevd
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("couldn't bind uevent socket: %w", err)
+	}
+	c := &udevCollector{
+		events: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "udev", "events_total"),
+			"Number of device events sent by the kernel since the start of the exporter.",
+			[]string{"action", "subsystem"}, nil,
+		), prometheus.CounterValue},
+		counts: map[udevEventKey]uint64{},
+		logger: logger,
+		socket: os.NewFile(uintptr(fd), "uevent"),
+	}
+	go c.receive()
+	return c, nil
+}
+
+// Close implements closer and stops counting events.
+func (c *udevCollector) Close() error {
+	return c.socket.Close()
+}
+
+// Update implements Collector. The counters are cumulative, not reset on
+// read - otherwise concurrent scrapes would steal each other's events.
+func (c *udevCollector) Update(ch chan<- prometheus.Metric) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for key, count := range c.counts {
+		ch <- c.events.mustNewConstMetric(float64(count), key.action, key.subsystem)
+	}
+	return nil
+}
+
+func (c *udevCollector) receive() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := c.socket.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrClosed) {
+				return
+			}
+			if errors.Is(err, unix.EINTR) || errors.Is(err, unix.ENOBUFS) {
+				// ENOBUFS: events got lost because we were too slow
+				level.Debug(c.logger).Log("msg", "uevent receive failed", "err", err)
+				continue
+			}
+			level.Error(c.logger).Log("msg", "uevent receive failed, stop counting events", "err", err)
+			return
+		}
+		if key, ok := parseUevent(buf[:n]); ok {
+			c.mtx.Lock()
+			c.counts[key]++
+			c.mtx.Unlock()
+		}
+	}
+}
+
+// parseUevent extracts action and subsystem of a kernel uevent, which is a
+// "<action>@<devpath>" header followed by NUL separated KEY=value pairs.
+func parseUevent(msg []byte) (udevEventKey, bool) {
+	var key udevEventKey
+	for _, field := range bytes.Split(msg, []byte{0}) {
+		switch {
+		case bytes.HasPrefix(field, []byte("ACTION=")):
+			key.action = string(field[len("ACTION="):])
+		case bytes.HasPrefix(field, []byte("SUBSYSTEM=")):
+			key.subsystem = string(field[len("SUBSYSTEM="):])
+		}
+	}
+	return key, key.action != ""
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noudev
+// +build !noudev
+
+package collector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUevent(t *testing.T) {
+	for _, tc := range []struct {
+		msg  string
+		want udevEventKey
+		ok   bool
+	}{
+		{"add@/devices/pci0000:00/0000:00:14.0/usb1/1-2\x00ACTION=add\x00DEVPATH=/devices/pci0000:00/0000:00:14.0/usb1/1-2\x00SUBSYSTEM=usb\x00DEVTYPE=usb_device\x00SEQNUM=4711",
+			udevEventKey{"add", "usb"}, true},
+		{"change@/devices/virtual/block/loop0\x00ACTION=change\x00DEVPATH=/devices/virtual/block/loop0\x00SUBSYSTEM=block\x00SEQNUM=4712\x00",
+			udevEventKey{"change", "block"}, true},
+		// libudev messages of group 2 have a binary header without ACTION
+		{"libudev\x00\xfe\xed\xca\xfe", udevEventKey{}, false},
+	} {
+		got, ok := parseUevent([]byte(tc.msg))
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("%q: want %+v %t, got %+v %t", strings.SplitN(tc.msg, "\x00", 2)[0], tc.want, tc.ok, got, ok)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nouncore
+// +build !nouncore
+
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// uncoreUPIEvent is UNC_UPI_TxL_FLITS.ALL_DATA. 9 data flits transfer one
+// 64 byte cache line, see the Intel Xeon Scalable uncore performance
+// monitoring guide.
+const (
+	uncoreUPIEvent = "event=0x02,umask=0x0f"
+	uncoreUPIBytes = 64.0 / 9
+)
+
+type uncoreCollector struct {
+	upi      typedDesc
+	counters []pmuCounter
+	logger   log.Logger
+}
+
+func init() {
+	registerCollector("uncore", defaultDisabled, NewUncoreCollector)
+}
+
+// NewUncoreCollector returns a new Collector exposing the UPI link bandwidth
+// per socket using the uncore PMUs of Intel Xeon CPUs. The memory controller
+// bandwidth gets exposed by the memory_bandwidth collector. Requires
+// CAP_PERFMON (or CAP_SYS_ADMIN) or kernel.perf_event_paranoid <= 0.
+func NewUncoreCollector(logger log.Logger) (Collector, error) {
+	c := &uncoreCollector{
+		upi: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "uncore", "upi_bandwidth_bytes_total"),
+			"Number of data bytes sent over the UPI links of the socket.",
+			[]string{"socket"}, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}
+
+	pmus, err := filepath.Glob(sysFilePath("bus/event_source/devices/uncore_upi_*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, pmu := range pmus {
+		if err := c.open(pmu); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(pmu), err)
+		}
+	}
+	return c, nil
+}
+
+func (c *uncoreCollector) open(pmu string) error {
+	config, err := parsePMUEventTerms(pmu, uncoreUPIEvent)
+	if err != nil {
+		return err
+	}
+	counters, err := openUncorePMUCounters(pmu, config)
+	if err != nil {
+		return err
+	}
+	c.counters = append(c.counters, counters...)
+	return nil
+}
+
+// Close implements closer.
+func (c *uncoreCollector) Close() error {
+	closePMUCounters(c.counters)
+	c.counters = nil
+	return nil
+}
+
+// Update implements Collector and exposes the counters summed up per socket.
+func (c *uncoreCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.counters) == 0 {
+		level.Debug(c.logger).Log("msg", "No uncore UPI PMUs found")
+		return ErrNoData
+	}
+
+	sums := map[string]uint64{}
+	for _, counter := range c.counters {
+		value, err := readPMUCounter(counter)
+		if err != nil {
+			return err
+		}
+		sums[counter.socket] += value
+	}
+	for socket, value := range sums {
+		ch <- c.upi.mustNewConstMetric(float64(value)*uncoreUPIBytes, socket)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nousb
+// +build !nousb
+
+package collector
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	usbClassHub = "09"
+	// VBUS voltage
+	usbBusVolts = 5
+)
+
+type usbCollector struct {
+	info, maxPower, speed typedDesc
+	logger                log.Logger
+}
+
+func init() {
+	registerCollector("usb", defaultDisabled, NewUSBCollector)
+}
+
+// NewUSBCollector returns a new Collector exposing the attached USB devices.
+func NewUSBCollector(logger log.Logger) (Collector, error) {
+	return &usbCollector{
+		info: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "usb", "device_info"),
+			"Information about the USB device.",
+			[]string{"bus", "device", "vendor_id", "product_id", "manufacturer", "product"}, nil,
+		), prometheus.GaugeValue},
+		maxPower: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "usb", "device_max_power_milliwatts"),
+			"Max. power the USB device may draw from the bus.",
+			[]string{"bus", "device"}, nil,
+		), prometheus.GaugeValue},
+		speed: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "usb", "device_speed_mbps"),
+			"Negotiated speed of the USB device in Mbit/s.",
+			[]string{"bus", "device"}, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Hubs and interfaces (e.g. 1-1:1.0) get
+// skipped.
+func (c *usbCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := filepath.Glob(sysFilePath("bus/usb/devices/*"))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, dir := range devices {
+		device := filepath.Base(dir)
+		if strings.ContainsRune(device, ':') {
+			continue
+		}
+		if class, err := readStringFromFile(filepath.Join(dir, "bDeviceClass")); err != nil || class == usbClassHub {
+			continue
+		}
+		bus, err := readStringFromFile(filepath.Join(dir, "busnum"))
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read USB bus number", "device", device, "err", err)
+			continue
+		}
+
+		labels := []string{bus, device}
+		for _, file := range []string{"idVendor", "idProduct", "manufacturer", "product"} {
+			value, _ := readStringFromFile(filepath.Join(dir, file))
+			labels = append(labels, value)
+		}
+		ch <- c.info.mustNewConstMetric(1, labels...)
+
+		// e.g. "100mA"
+		if power, err := readStringFromFile(filepath.Join(dir, "bMaxPower")); err == nil {
+			if ma, err := strconv.ParseFloat(strings.TrimSuffix(power, "mA"), 64); err == nil {
+				ch <- c.maxPower.mustNewConstMetric(ma*usbBusVolts, bus, device)
+			}
+		}
+		// e.g. "1.5", "480" or "5000"
+		if speed, err := readStringFromFile(filepath.Join(dir, "speed")); err == nil {
+			if mbps, err := strconv.ParseFloat(speed, 64); err == nil {
+				ch <- c.speed.mustNewConstMetric(mbps, bus, device)
+			}
+		}
+		found = true
+	}
+	if !found {
+		level.Debug(c.logger).Log("msg", "No USB devices found")
+		return ErrNoData
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !novdso
+// +build !novdso
+
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var vdsoAllProcs = kingpin.Flag("collector.vdso.all-procs", "Scan the maps of all processes and expose the number of processes having the vDSO mapped.").Default("false").Bool()
+
+type vdsoCollector struct {
+	size, procs typedDesc
+	logger      log.Logger
+}
+
+func init() {
+	registerCollector("vdso", defaultDisabled, NewVDSOCollector)
+}
+
+// NewVDSOCollector returns a new Collector exposing the size of the vDSO
+// and vvar mappings.
+func NewVDSOCollector(logger log.Logger) (Collector, error) {
+	return &vdsoCollector{
+		size: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "vdso", "size_bytes"),
+			"Size of the vDSO (code) and vvar (data) mappings of the exporter process.",
+			[]string{"type"}, nil,
+		), prometheus.GaugeValue},
+		procs: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "vdso", "processes"),
+			"Number of processes having the vDSO mapped.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. The vDSO gets mapped into each process by
+// the kernel, so the exporter's own mappings are representative.
+func (c *vdsoCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("self/maps"))
+	if err != nil {
+		return err
+	}
+	sizes, err := parseVDSOMappings(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	if len(sizes) == 0 {
+		level.Debug(c.logger).Log("msg", "No vDSO mapping found")
+		return ErrNoData
+	}
+	for typ, size := range sizes {
+		ch <- c.size.mustNewConstMetric(float64(size), typ)
+	}
+
+	if *vdsoAllProcs {
+		ch <- c.procs.mustNewConstMetric(float64(c.countProcesses()))
+	}
+	return nil
+}
+
+// countProcesses returns the number of processes with a [vdso] mapping.
+// Processes gone or not readable get skipped, kernel threads have none.
+func (c *vdsoCollector) countProcesses() int {
+	files, err := filepath.Glob(procFilePath("[0-9]*/maps"))
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "Failed to list processes", "err", err)
+		return 0
+	}
+	n := 0
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		sizes, err := parseVDSOMappings(f)
+		f.Close()
+		if err == nil && sizes["vdso"] > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// parseVDSOMappings returns the size of the [vdso] and [vvar] mappings of
+// the given maps content by type.
+func parseVDSOMappings(r io.Reader) (map[string]uint64, error) {
+	sizes := make(map[string]uint64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 6 {
+			continue
+		}
+		var typ string
+		switch fields[5] {
+		case "[vdso]":
+			typ = "vdso"
+		case "[vvar]":
+			typ = "vvar"
+		default:
+			continue
+		}
+		bounds := strings.SplitN(fields[0], "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid address range %q", fields[0])
+		}
+		start, err := strconv.ParseUint(bounds[0], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		end, err := strconv.ParseUint(bounds[1], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		sizes[typ] += end - start
+	}
+	return sizes, scanner.Err()
+}
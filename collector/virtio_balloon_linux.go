@@ -0,0 +1,93 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !novirtio_balloon
+// +build !novirtio_balloon
+
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// virtioBalloonStats maps the statistics files to their metric name, help and
+// value type.
+var virtioBalloonStats = []struct {
+	file, name, help string
+	valueType        prometheus.ValueType
+}{
+	{"swap_in", "swap_in_total", "Number of pages swapped in.", prometheus.CounterValue},
+	{"swap_out", "swap_out_total", "Number of pages swapped out.", prometheus.CounterValue},
+	{"major_faults", "major_faults_total", "Number of major page faults.", prometheus.CounterValue},
+	{"minor_faults", "minor_faults_total", "Number of minor page faults.", prometheus.CounterValue},
+	{"free_commands", "free_commands_total", "Number of free page hinting commands.", prometheus.CounterValue},
+	{"free_pages", "free_pages", "Number of free pages.", prometheus.GaugeValue},
+	{"total_pages", "total_pages", "Total number of pages.", prometheus.GaugeValue},
+}
+
+type virtioBalloonCollector struct {
+	descs  []typedDesc
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("virtio_balloon", defaultDisabled, NewVirtioBalloonCollector)
+}
+
+// NewVirtioBalloonCollector returns a new Collector exposing VirtIO balloon
+// device stats.
+func NewVirtioBalloonCollector(logger log.Logger) (Collector, error) {
+	c := &virtioBalloonCollector{logger: logger}
+	for _, s := range virtioBalloonStats {
+		c.descs = append(c.descs, typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "virtio_balloon", s.name),
+			s.help,
+			[]string{"device"}, nil,
+		), s.valueType})
+	}
+	return c, nil
+}
+
+// Update implements Collector. Statistics not provided by the device get
+// skipped.
+func (c *virtioBalloonCollector) Update(ch chan<- prometheus.Metric) error {
+	dirs, err := filepath.Glob(sysFilePath("bus/virtio/drivers/virtio_balloon/virtio*/statistics"))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		level.Debug(c.logger).Log("msg", "No virtio_balloon devices found")
+		return ErrNoData
+	}
+
+	for _, dir := range dirs {
+		device := filepath.Base(filepath.Dir(dir))
+		for i, s := range virtioBalloonStats {
+			value, err := readUintFromFile(filepath.Join(dir, s.file))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return err
+			}
+			ch <- c.descs[i].mustNewConstMetric(float64(value), device)
+		}
+	}
+	return nil
+}
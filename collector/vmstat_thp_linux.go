@@ -0,0 +1,112 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !novmstat_thp
+// +build !novmstat_thp
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const thpSubsystem = "thp"
+
+// thpFields are the /proc/vmstat fields exposed by the thpCollector.
+var thpFields = []string{
+	"thp_fault_alloc",
+	"thp_fault_fallback",
+	"thp_collapse_alloc",
+	"thp_collapse_alloc_failed",
+	"thp_file_alloc",
+	"thp_file_fallback",
+	"thp_split_page",
+	"thp_split_pmd",
+	"thp_deferred_split_page",
+	"thp_swpout",
+	"thp_swpout_fallback",
+}
+
+type thpCollector struct {
+	counters map[string]typedDesc
+	enabled  typedDesc
+	logger   log.Logger
+}
+
+func init() {
+	registerCollector("vmstat_thp", defaultDisabled, NewTHPCollector)
+}
+
+// NewTHPCollector returns a new Collector exposing Transparent Hugepage
+// statistics.
+func NewTHPCollector(logger log.Logger) (Collector, error) {
+	counters := make(map[string]typedDesc, len(thpFields))
+	for _, field := range thpFields {
+		counters[field] = typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, thpSubsystem, strings.TrimPrefix(field, "thp_")+"_total"),
+			fmt.Sprintf("/proc/vmstat information field %s.", field),
+			nil, nil,
+		), prometheus.CounterValue}
+	}
+	return &thpCollector{
+		counters: counters,
+		enabled: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, thpSubsystem, "enabled"),
+			"Whether Transparent Hugepages are enabled (always or madvise) or not.",
+			nil, nil,
+		), prometheus.GaugeValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector and exposes the THP fields of /proc/vmstat.
+func (c *thpCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("vmstat"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for field, desc := range c.counters {
+		if value, ok := stats[field]; ok {
+			ch <- desc.mustNewConstMetric(float64(value))
+		}
+	}
+
+	data, err := readStringFromFile(sysFilePath("kernel/mm/transparent_hugepage/enabled"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Kernel without THP support")
+			return nil
+		}
+		return err
+	}
+	enabled := 0.0
+	switch parseSysfsSelection(data) {
+	case "always", "madvise":
+		enabled = 1
+	}
+	ch <- c.enabled.mustNewConstMetric(enabled)
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !novmstat_thp
+// +build !novmstat_thp
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTHPCollector(t *testing.T) {
+	*procPath = "fixtures/proc"
+	*sysPath = "fixtures/thp"
+	c, err := NewTHPCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_thp_collapse_alloc_failed_total /proc/vmstat information field thp_collapse_alloc_failed.
+# TYPE node_thp_collapse_alloc_failed_total counter
+node_thp_collapse_alloc_failed_total 20954
+# HELP node_thp_collapse_alloc_total /proc/vmstat information field thp_collapse_alloc.
+# TYPE node_thp_collapse_alloc_total counter
+node_thp_collapse_alloc_total 88421
+# HELP node_thp_enabled Whether Transparent Hugepages are enabled (always or madvise) or not.
+# TYPE node_thp_enabled gauge
+node_thp_enabled 1
+# HELP node_thp_fault_alloc_total /proc/vmstat information field thp_fault_alloc.
+# TYPE node_thp_fault_alloc_total counter
+node_thp_fault_alloc_total 142261
+# HELP node_thp_fault_fallback_total /proc/vmstat information field thp_fault_fallback.
+# TYPE node_thp_fault_fallback_total counter
+node_thp_fault_fallback_total 98119
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
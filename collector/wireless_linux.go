@@ -0,0 +1,153 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nowireless
+// +build !nowireless
+
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// wirelessNoValue is what drivers report for levels they do not measure.
+const wirelessNoValue = -256
+
+type wirelessStats struct {
+	iface              string
+	link, level, noise float64
+	discarded, retries uint64
+}
+
+type wirelessCollector struct {
+	link, signal, noise, rxErrors, txRetries typedDesc
+	logger                                   log.Logger
+}
+
+func init() {
+	registerCollector("wireless", defaultEnabled, NewWirelessCollector)
+}
+
+// NewWirelessCollector returns a new Collector exposing the wireless
+// extension stats of /proc/net/wireless.
+func NewWirelessCollector(logger log.Logger) (Collector, error) {
+	labels := []string{"interface"}
+	return &wirelessCollector{
+		link: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless", "link_quality"),
+			"Link quality of the interface as reported by the driver.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		signal: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless", "signal_dbm"),
+			"Signal level of the interface in dBm.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		noise: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless", "noise_dbm"),
+			"Noise level of the interface in dBm.",
+			labels, nil,
+		), prometheus.GaugeValue},
+		rxErrors: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless", "rx_errors_total"),
+			"Number of received packets discarded due to a wrong network ID, decryption or fragment reassembly failures and other reasons.",
+			labels, nil,
+		), prometheus.CounterValue},
+		txRetries: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless", "tx_retries_total"),
+			"Number of packets discarded because the maximum number of MAC retries was reached.",
+			labels, nil,
+		), prometheus.CounterValue},
+		logger: logger,
+	}, nil
+}
+
+// Update implements Collector. Drivers not reporting the noise level get
+// no node_wireless_noise_dbm metric.
+func (c *wirelessCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/wireless"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Kernel without wireless extensions")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseProcNetWireless(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	if len(stats) == 0 {
+		level.Debug(c.logger).Log("msg", "No wireless interfaces found")
+		return ErrNoData
+	}
+	for _, s := range stats {
+		ch <- c.link.mustNewConstMetric(s.link, s.iface)
+		ch <- c.signal.mustNewConstMetric(s.level, s.iface)
+		if s.noise != wirelessNoValue {
+			ch <- c.noise.mustNewConstMetric(s.noise, s.iface)
+		}
+		ch <- c.rxErrors.mustNewConstMetric(float64(s.discarded), s.iface)
+		ch <- c.txRetries.mustNewConstMetric(float64(s.retries), s.iface)
+	}
+	return nil
+}
+
+// parseProcNetWireless parses /proc/net/wireless, which has two header lines
+// followed by one line per interface:
+// "wlan0: 0000   54.  -56.  -256        0      0      0      0    160        0"
+// i.e. status, link, level and noise (a trailing dot marks values updated
+// since the last read), the discarded nwid, crypt, frag, retry and misc
+// counters and the missed beacons (not exposed).
+func parseProcNetWireless(r io.Reader) ([]wirelessStats, error) {
+	var stats []wirelessStats
+	scanner := bufio.NewScanner(r)
+	for n := 0; scanner.Scan(); n++ {
+		if n < 2 {
+			continue
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("unexpected line %q", scanner.Text())
+		}
+		var values [10]float64
+		for i, field := range fields[1:11] {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(field, "."), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q: %w", field, err)
+			}
+			values[i] = v
+		}
+		stats = append(stats, wirelessStats{
+			iface:     strings.TrimSuffix(fields[0], ":"),
+			link:      values[1],
+			level:     values[2],
+			noise:     values[3],
+			discarded: uint64(values[4] + values[5] + values[6] + values[8]),
+			retries:   uint64(values[7]),
+		})
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nowireless
+// +build !nowireless
+
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProcNetWireless(t *testing.T) {
+	stats, err := parseProcNetWireless(strings.NewReader(`Inter-| sta-|   Quality        |   Discarded packets               | Missed | WE
+ face | tus | link level noise |  nwid  crypt   frag  retry   misc | beacon | 22
+ wlan0: 0000   54.  -56.  -256        1      2      3      7      4        5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []wirelessStats{{
+		iface: "wlan0", link: 54, level: -56, noise: wirelessNoValue,
+		discarded: 10, retries: 7,
+	}}
+	if !reflect.DeepEqual(want, stats) {
+		t.Errorf("want %+v, got %+v", want, stats)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nowriteback && !nodiskstats
+// +build !nowriteback,!nodiskstats
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Indices of the flush fields in /sys/block/*/stat, see
+// Documentation/block/stat.rst. They are available since Linux 5.5, only.
+const (
+	writebackFlushIOs   = 15
+	writebackFlushTicks = 16
+)
+
+// writebackVmstat maps the writeback related /proc/vmstat fields to their
+// metric name and value type.
+var writebackVmstat = map[string]struct {
+	name      string
+	valueType prometheus.ValueType
+}{
+	"nr_dirty":     {"dirty_pages", prometheus.GaugeValue},
+	"nr_writeback": {"in_progress_pages", prometheus.GaugeValue},
+	"nr_dirtied":   {"system_dirtied_pages_total", prometheus.CounterValue},
+	"nr_written":   {"system_written_pages_total", prometheus.CounterValue},
+}
+
+type writebackCollector struct {
+	flushes, time         typedDesc
+	ignoredDevicesPattern *regexp.Regexp
+	logger                log.Logger
+}
+
+func init() {
+	registerCollector("writeback", defaultDisabled, NewWritebackCollector)
+}
+
+// NewWritebackCollector returns a new Collector exposing page writeback
+// stats. Devices ignored by the diskstats collector
+// (--collector.diskstats.ignored-devices) get skipped, so it needs to be
+// built in.
+func NewWritebackCollector(logger log.Logger) (Collector, error) {
+	return &writebackCollector{
+		flushes: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "writeback", "flushes_total"),
+			"Number of flush requests completed successfully by the block device.",
+			[]string{"device"}, nil,
+		), prometheus.CounterValue},
+		time: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "writeback", "time_seconds_total"),
+			"Total time spent on flush requests of the block device.",
+			[]string{"device"}, nil,
+		), prometheus.CounterValue},
+		ignoredDevicesPattern: regexp.MustCompile(*ignoredDevices),
+		logger:                logger,
+	}, nil
+}
+
+// Update implements Collector.
+func (c *writebackCollector) Update(ch chan<- prometheus.Metric) error {
+	if err := c.updateVmstat(ch); err != nil {
+		return err
+	}
+	return c.updateBlock(ch)
+}
+
+func (c *writebackCollector) updateVmstat(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("vmstat"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for field, m := range writebackVmstat {
+		value, ok := stats[field]
+		if !ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "writeback", m.name),
+				fmt.Sprintf("/proc/vmstat information field %s.", field),
+				nil, nil),
+			m.valueType,
+			float64(value),
+		)
+	}
+	return nil
+}
+
+// updateBlock exposes the flush requests of each block device. All other
+// fields of the stat file are exposed by the diskstats collector.
+func (c *writebackCollector) updateBlock(ch chan<- prometheus.Metric) error {
+	files, err := filepath.Glob(sysFilePath("block/*/stat"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		device := filepath.Base(filepath.Dir(file))
+		if c.ignoredDevicesPattern.MatchString(device) {
+			level.Debug(c.logger).Log("msg", "Ignoring device", "device", device, "pattern", c.ignoredDevicesPattern)
+			continue
+		}
+		data, err := readStringFromFile(file)
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "Failed to read block device stats", "device", device, "err", err)
+			continue
+		}
+		fields := strings.Fields(data)
+		if len(fields) <= writebackFlushTicks {
+			level.Debug(c.logger).Log("msg", "No flush stats, Linux < 5.5?", "device", device)
+			continue
+		}
+		flushes, err := strconv.ParseUint(fields[writebackFlushIOs], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid flush count in %s: %w", file, err)
+		}
+		ticks, err := strconv.ParseUint(fields[writebackFlushTicks], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid flush time in %s: %w", file, err)
+		}
+		ch <- c.flushes.mustNewConstMetric(float64(flushes), device)
+		ch <- c.time.mustNewConstMetric(float64(ticks)/1000, device)
+	}
+	return nil
+}
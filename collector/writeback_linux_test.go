@@ -0,0 +1,57 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !nowriteback && !nodiskstats
+// +build !nowriteback,!nodiskstats
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWritebackCollector(t *testing.T) {
+	*procPath = "fixtures/proc"
+	*sysPath = "fixtures/writeback"
+	*ignoredDevices = "^(ram|loop|fd|(h|s|v|xv)d[a-z]|nvme\\d+n\\d+p)\\d+$"
+	c, err := NewWritebackCollector(log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node_writeback_dirty_pages /proc/vmstat information field nr_dirty.
+# TYPE node_writeback_dirty_pages gauge
+node_writeback_dirty_pages 21
+# HELP node_writeback_flushes_total Number of flush requests completed successfully by the block device.
+# TYPE node_writeback_flushes_total counter
+node_writeback_flushes_total{device="sda"} 62104
+# HELP node_writeback_in_progress_pages /proc/vmstat information field nr_writeback.
+# TYPE node_writeback_in_progress_pages gauge
+node_writeback_in_progress_pages 0
+# HELP node_writeback_system_dirtied_pages_total /proc/vmstat information field nr_dirtied.
+# TYPE node_writeback_system_dirtied_pages_total counter
+node_writeback_system_dirtied_pages_total 1.1127183e+07
+# HELP node_writeback_system_written_pages_total /proc/vmstat information field nr_written.
+# TYPE node_writeback_system_written_pages_total counter
+node_writeback_system_written_pages_total 1.1122061e+07
+# HELP node_writeback_time_seconds_total Total time spent on flush requests of the block device.
+# TYPE node_writeback_time_seconds_total counter
+node_writeback_time_seconds_total{device="sda"} 60.136
+`
+	if err := testutil.CollectAndCompare(updateCollector{c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
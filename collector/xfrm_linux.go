@@ -0,0 +1,79 @@
+// Copyright 2021 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !noxfrm
+// +build !noxfrm
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const xfrmSubsystem = "xfrm"
+
+type xfrmCollector struct {
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("xfrm", defaultEnabled, NewXfrmCollector)
+}
+
+// NewXfrmCollector returns a new Collector exposing IPsec transform stats.
+func NewXfrmCollector(logger log.Logger) (Collector, error) {
+	return &xfrmCollector{logger: logger}, nil
+}
+
+// Update implements Collector and exposes /proc/net/xfrm_stat. The kernel
+// sums up the per-CPU counters already, there is no per-CPU variant.
+func (c *xfrmCollector) Update(ch chan<- prometheus.Metric) error {
+	file, err := os.Open(procFilePath("net/xfrm_stat"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			level.Debug(c.logger).Log("msg", "Kernel without XFRM support")
+			return ErrNoData
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := parseKeyValueStats(file)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+	}
+	for name, value := range stats {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, xfrmSubsystem, xfrmMetricName(name)+"_total"),
+				fmt.Sprintf("/proc/net/xfrm_stat information field %s.", name),
+				nil, nil),
+			prometheus.CounterValue,
+			float64(value),
+		)
+	}
+	return nil
+}
+
+// xfrmMetricName converts field names like XfrmInStateSeqError into
+// in_state_seq_error.
+func xfrmMetricName(field string) string {
+	return camelCaseToSnake(strings.TrimPrefix(field, "Xfrm"))
+}
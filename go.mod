@@ -12,6 +12,7 @@ require (
 	github.com/jsimonetti/rtnetlink v0.0.0-20211022192332-93da33804786
 	github.com/lufia/iostat v1.2.0
 	github.com/mattn/go-xmlrpc v0.0.3
+	github.com/mdlayher/netlink v1.4.1
 	github.com/mdlayher/wifi v0.0.0-20200527114002-84f0b9457fdd
 	github.com/prometheus/client_golang v1.11.0
 	github.com/prometheus/client_model v0.2.0
@@ -21,8 +21,12 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"os/user"
 	"sort"
+	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/prometheus/common/promlog"
 	"github.com/prometheus/common/promlog/flag"
@@ -43,21 +47,25 @@ import (
 // created on the fly, if filtering is requested. Create instances with
 // newHandler.
 type handler struct {
+	// mtx guards unfilteredHandler and keeps scrapes and reloads apart.
+	mtx               sync.RWMutex
 	unfilteredHandler http.Handler
 	// exporterMetricsRegistry is a separate registry for the metrics about
 	// the exporter itself.
 	exporterMetricsRegistry *prometheus.Registry
 	includeExporterMetrics  bool
 	includeGoMetrics        bool
+	disableCompression      bool
 	maxRequests             int
 	logger                  log.Logger
 }
 
-func newHandler(includeExporterMetrics bool, includeGoMetrics bool, maxRequests int, logger log.Logger) *handler {
+func newHandler(includeExporterMetrics bool, includeGoMetrics bool, disableCompression bool, maxRequests int, logger log.Logger) *handler {
 	h := &handler{
 		exporterMetricsRegistry: prometheus.NewRegistry(),
 		includeExporterMetrics:  includeExporterMetrics,
 		includeGoMetrics:        includeGoMetrics,
+		disableCompression:      disableCompression,
 		maxRequests:             maxRequests,
 		logger:                  logger,
 	}
@@ -86,6 +94,9 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	filters := r.URL.Query()["collect[]"]
 	level.Debug(h.logger).Log("msg", "collect query:", "filters", filters)
 
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
 	if len(filters) == 0 {
 		// No filters, use the prepared unfiltered handler.
 		h.unfilteredHandler.ServeHTTP(w, r)
@@ -102,6 +113,42 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	filteredHandler.ServeHTTP(w, r)
 }
 
+// reload parses the command line again, re-creates the collectors whose
+// flags changed and replaces the unfiltered handler. Changed flags, which
+// cannot be applied at runtime, get logged.
+func (h *handler) reload(disableDefaultCollectors, compact *bool) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	other, err := collector.ReloadFlags(kingpin.CommandLine, os.Args[1:], h.logger)
+	if err != nil {
+		return fmt.Errorf("couldn't parse command line: %s", err)
+	}
+	if *disableDefaultCollectors {
+		collector.DisableDefaultCollectors()
+	}
+	var ignored []string
+	for _, name := range other {
+		switch name {
+		case "compact":
+			expfmt.Comments = !*compact
+		case "collector.disable-defaults":
+		default:
+			ignored = append(ignored, name)
+		}
+	}
+	if len(ignored) > 0 {
+		level.Warn(h.logger).Log("msg", "Changed flags take effect after a restart, only", "flags", strings.Join(ignored, ","))
+	}
+
+	innerHandler, err := h.innerHandler()
+	if err != nil {
+		return err
+	}
+	h.unfilteredHandler = innerHandler
+	return nil
+}
+
 // innerHandler is used to create both the one unfiltered http.Handler to be
 // wrapped by the outer handler and also the filtered handlers created on the
 // fly. The former is accomplished by calling innerHandler without any arguments
@@ -127,7 +174,7 @@ func (h *handler) innerHandler(filters ...string) (http.Handler, error) {
 		}
 	}
 
-	r := prometheus.NewRegistry()
+	r := collector.NewSafeRegistry(h.logger)
 	r.MustRegister(version.NewCollector("node_exporter"))
 	if err := r.Register(nc); err != nil {
 		return nil, fmt.Errorf("couldn't register node collector: %s", err)
@@ -139,6 +186,9 @@ func (h *handler) innerHandler(filters ...string) (http.Handler, error) {
 			ErrorHandling:       promhttp.ContinueOnError,
 			MaxRequestsInFlight: h.maxRequests,
 			Registry:            h.exporterMetricsRegistry,
+			// promhttp negotiates gzip via Accept-Encoding and pools the
+			// gzip writers.
+			DisableCompression: h.disableCompression,
 		},
 	)
 	if h.includeExporterMetrics {
@@ -169,10 +219,18 @@ func main() {
 			"web.disable-go-metrics",
 			"Exclude go_* metrics about the exporter itself.",
 		).Bool()
+		disableCompression = kingpin.Flag(
+			"web.disable-compression",
+			"Never gzip the metrics, even if the scraper accepts it.",
+		).Bool()
 		maxRequests = kingpin.Flag(
 			"web.max-requests",
 			"Maximum number of parallel scrape requests. Use 0 to disable.",
 		).Default("40").Int()
+		enableLifecycle = kingpin.Flag(
+			"web.enable-lifecycle",
+			"Enable reloading the command line (incl. @file arguments) via HTTP POST to /-/reload.",
+		).Default("false").Bool()
 		disableDefaultCollectors = kingpin.Flag(
 			"collector.disable-defaults",
 			"Set all collectors to disabled by default.",
@@ -204,7 +262,36 @@ func main() {
 		level.Warn(logger).Log("msg", "Node Exporter is running as root user. This exporter is designed to run as unpriviledged user, root is not required.")
 	}
 
-	http.Handle(*metricsPath, newHandler(!*disableExporterMetrics, !*disableGoMetrics, *maxRequests, logger))
+	h := newHandler(!*disableExporterMetrics, !*disableGoMetrics, *disableCompression, *maxRequests, logger)
+	http.Handle(*metricsPath, h)
+	reload := func() error {
+		err := h.reload(disableDefaultCollectors, compact)
+		if err != nil {
+			level.Error(logger).Log("msg", "Reload failed", "err", err)
+		} else {
+			level.Info(logger).Log("msg", "Reload completed")
+		}
+		return err
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reload()
+		}
+	}()
+	if *enableLifecycle {
+		http.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				w.Write([]byte("This endpoint requires a POST request.\n"))
+				return
+			}
+			if err := reload(); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+	}
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
 			<head><title>Node Exporter</title></head>
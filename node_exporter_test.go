@@ -17,12 +17,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/prometheus/procfs"
 )
 
@@ -152,3 +154,23 @@ func runCommandAndTests(cmd *exec.Cmd, address string, fn func(pid int) error) e
 	}
 	return err
 }
+
+func BenchmarkHandlerCompression(b *testing.B) {
+	for _, disable := range []bool{false, true} {
+		h := newHandler(true, true, disable, 0, log.NewNopLogger())
+		b.Run(fmt.Sprintf("disable-compression=%t", disable), func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest("GET", "/metrics", nil)
+				req.Header.Set("Accept-Encoding", "gzip")
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if got, want := rec.Header().Get("Content-Encoding") == "gzip", !disable; got != want {
+					b.Fatalf("want gzip %t, got %t", want, got)
+				}
+				size = rec.Body.Len()
+			}
+			b.ReportMetric(float64(size), "bytes/scrape")
+		})
+	}
+}